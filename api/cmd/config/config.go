@@ -16,6 +16,25 @@ type (
 		SecretKey string
 		Prod      bool
 		UploadsDir  string
+		LobbyBus    string //"memory" (una sola instancia) o "postgres"
+		Websocket   *Websocket
+		Scheduler   *Scheduler
+	}
+
+	// salas con horario de inicio, los tiempos en segundos
+	Scheduler struct {
+		Enabled      bool
+		Interval     int    //cada cuanto se revisan las salas programadas
+		ReminderLead int    //anticipacion del recordatorio
+		CloseWindow  int    //plazo desde el inicio para empezar la votacion, despues se cierra la sala
+		WebhookURL   string //destino de los avisos, vacio solo los registra en el log
+	}
+
+	// tiempos en segundos del heartbeat de las salas en vivo
+	Websocket struct {
+		PingInterval int //cada cuanto se envia un ping
+		PongWait     int //sin pong en este tiempo la conexion se da por muerta
+		WriteWait    int //tiempo maximo para escribir un mensaje
 	}
 
 	Server struct {
@@ -76,6 +95,20 @@ func GetConfig() *Config {
 
 		origins := os.Getenv("ALLOWED_CORS")
 
+		websocket := &Websocket{
+			PingInterval: envSeconds("WS_PING_INTERVAL", 25),
+			PongWait:     envSeconds("WS_PONG_WAIT", 60),
+			WriteWait:    envSeconds("WS_WRITE_WAIT", 10),
+		}
+
+		scheduler := &Scheduler{
+			Enabled:      os.Getenv("SCHEDULER_ENABLED") != "false",
+			Interval:     envSeconds("SCHEDULER_INTERVAL", 30),
+			ReminderLead: envSeconds("SCHEDULER_REMINDER_LEAD", 900),
+			CloseWindow:  envSeconds("SCHEDULER_CLOSE_WINDOW", 3600),
+			WebhookURL:   os.Getenv("SCHEDULER_WEBHOOK_URL"),
+		}
+
 		server := &Server{
 			Port:        apiPort,
 			AllowedCORS: origins,
@@ -87,8 +120,20 @@ func GetConfig() *Config {
 			SecretKey: secretKey,
 			Prod:      os.Getenv("PROD") == "true",
 			UploadsDir:  os.Getenv("UPLOADS_DIR"),
+			LobbyBus:    os.Getenv("LOBBY_BUS"),
+			Websocket:   websocket,
+			Scheduler:   scheduler,
 		}
 	})
 
 	return configInstance
 }
+
+// lee una variable de entorno en segundos, si falta o es invalida usa el valor por defecto
+func envSeconds(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
@@ -24,13 +24,7 @@ var engine *xorm.Engine
 func NewPostgresDatabase(conf *config.Config) Database {
 	once.Do(func() {
         // Construyes el DSN  
-        dsn := fmt.Sprintf(
-            "host=%s user=%s password=%s dbname=%s port=5432 sslmode=disable TimeZone=America/Argentina/Buenos_Aires",
-            conf.Db.Host,
-            conf.Db.User,
-            conf.Db.Password,
-            conf.Db.DBName,
-        )
+        dsn := DSN(conf)
 
         // 🔍 Loggea las variables críticas
         // log.Printf("▶️  POSTGRES HOST    = %q", conf.Db.Host)
@@ -50,6 +44,16 @@ func NewPostgresDatabase(conf *config.Config) Database {
     return dbInstance
 }
 
+// DSN de la base, tambien lo usa el listener del bus de salas
+func DSN(conf *config.Config) string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=5432 sslmode=disable TimeZone=America/Argentina/Buenos_Aires",
+		conf.Db.Host,
+		conf.Db.User,
+		conf.Db.Password,
+		conf.Db.DBName,
+	)
+}
 
 func (p *postgresDatabase) GetDb() *xorm.Engine {
 	return dbInstance.Db
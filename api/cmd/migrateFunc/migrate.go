@@ -5,9 +5,11 @@ import (
 	"strings"
 	"suffgo/cmd/config"
 	"suffgo/cmd/database"
+	dl "suffgo/internal/delegations/infrastructure/models"
 	o "suffgo/internal/options/infrastructure/models"
 	p "suffgo/internal/proposals/infrastructure/models"
 	r "suffgo/internal/rooms/infrastructure/models"
+	sc "suffgo/internal/scheduler/infrastructure/models"
 	s "suffgo/internal/settingsRoom/infrastructure/models"
 	ur "suffgo/internal/userRooms/infrastructure/models"
 	m "suffgo/internal/users/infrastructure/models"
@@ -53,6 +55,16 @@ func Make() error {
 		return err
 	}
 
+	err = MigrateDelegation(db)
+	if err != nil {
+		return err
+	}
+
+	err = MigrateSchedule(db)
+	if err != nil {
+		return err
+	}
+
 	err = MakeConstraints(db)
 	if err != nil {
 		fmt.Printf("Error al agregar la clave foránea: %v\n", err)
@@ -75,7 +87,7 @@ func MigrateUser(db database.Database) error {
 }
 
 func MigrateRoom(db database.Database) error {
-	err := db.GetDb().Sync2(new(r.Room), new(ur.UserRoom))
+	err := db.GetDb().Sync2(new(r.Room), new(ur.UserRoom), new(r.LobbyState), new(r.RoomRole), new(r.ChatMessage), new(r.RoomStateTransition), new(r.VotingSession), new(r.LobbyBusPayload))
 
 	if err != nil {
 		panic(err)
@@ -87,7 +99,7 @@ func MigrateRoom(db database.Database) error {
 }
 
 func MigrateProposal(db database.Database) error {
-	err := db.GetDb().Sync2(new(p.Proposal))
+	err := db.GetDb().Sync2(new(p.Proposal), new(p.ProposalRound), new(p.ProposalOutcome))
 
 	if err != nil {
 		return err
@@ -122,7 +134,7 @@ func MigrateRoomSetting(db database.Database) error {
 }
 
 func MigrateVote(db database.Database) error {
-	err := db.GetDb().Sync2(new(e.Vote))
+	err := db.GetDb().Sync2(new(e.Vote), new(e.VoteParticipation), new(e.VoteHistory))
 
 	if err != nil {
 		return err
@@ -133,6 +145,30 @@ func MigrateVote(db database.Database) error {
 	return nil
 }
 
+func MigrateDelegation(db database.Database) error {
+	err := db.GetDb().Sync2(new(dl.Delegation))
+
+	if err != nil {
+		return err
+	} else {
+		fmt.Printf("Se ha migrado Delegation con exito\n")
+	}
+
+	return nil
+}
+
+func MigrateSchedule(db database.Database) error {
+	err := db.GetDb().Sync2(new(sc.RoomSchedule))
+
+	if err != nil {
+		return err
+	} else {
+		fmt.Printf("Se ha migrado RoomSchedule con exito\n")
+	}
+
+	return nil
+}
+
 func MakeConstraints(db database.Database) error {
     statements := []struct {
         sql  string
@@ -170,6 +206,136 @@ func MakeConstraints(db database.Database) error {
             `ALTER TABLE vote ADD CONSTRAINT fk_option FOREIGN KEY (option_id) REFERENCES option(id)`,
             "fk_option on vote",
         },
+        {
+            `ALTER TABLE vote ADD CONSTRAINT fk_proxy FOREIGN KEY (proxy_id) REFERENCES users(id)`,
+            "fk_proxy on vote",
+        },
+        {
+            `ALTER TABLE vote ALTER COLUMN user_id DROP NOT NULL`,
+            "nullable user_id on vote (votacion secreta)",
+        },
+        {
+            `ALTER TABLE vote ALTER COLUMN option_id DROP NOT NULL`,
+            "nullable option_id on vote (abstenciones y votos en blanco)",
+        },
+        {
+            `ALTER TABLE vote ADD CONSTRAINT fk_proposal FOREIGN KEY (proposal_id) REFERENCES proposal(id) ON DELETE CASCADE`,
+            "fk_proposal on vote",
+        },
+        {
+            `ALTER TABLE vote_participation ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id)`,
+            "fk_user on vote_participation",
+        },
+        {
+            `ALTER TABLE vote_participation ADD CONSTRAINT fk_proposal FOREIGN KEY (proposal_id) REFERENCES proposal(id) ON DELETE CASCADE`,
+            "fk_proposal on vote_participation",
+        },
+        {
+            `ALTER TABLE delegation ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on delegation",
+        },
+        {
+            `ALTER TABLE delegation ADD CONSTRAINT fk_delegator FOREIGN KEY (delegator_id) REFERENCES users(id)`,
+            "fk_delegator on delegation",
+        },
+        {
+            `ALTER TABLE delegation ADD CONSTRAINT fk_delegate FOREIGN KEY (delegate_id) REFERENCES users(id)`,
+            "fk_delegate on delegation",
+        },
+        {
+            `ALTER TABLE lobby_state ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on lobby_state",
+        },
+        {
+            `ALTER TABLE room_role ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on room_role",
+        },
+        {
+            `ALTER TABLE room_role ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id)`,
+            "fk_user on room_role",
+        },
+        {
+            `ALTER TABLE chat_message ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on chat_message",
+        },
+        {
+            `ALTER TABLE chat_message ADD CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id)`,
+            "fk_user on chat_message",
+        },
+        {
+            `ALTER TABLE room_schedule ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on room_schedule",
+        },
+        {
+            `ALTER TABLE room_state_transition ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on room_state_transition",
+        },
+        {
+            `ALTER TABLE voting_session ADD CONSTRAINT fk_room FOREIGN KEY (room_id) REFERENCES room(id) ON DELETE CASCADE`,
+            "fk_room on voting_session",
+        },
+        {
+            `ALTER TABLE proposal_round ADD CONSTRAINT fk_proposal FOREIGN KEY (proposal_id) REFERENCES proposal(id) ON DELETE CASCADE`,
+            "fk_proposal on proposal_round",
+        },
+        {
+            `CREATE UNIQUE INDEX IF NOT EXISTS voting_session_active_idx ON voting_session(room_id) WHERE active`,
+            "voting_session_active_idx unique index on voting_session(room_id) (una sesion activa por sala)",
+        },
+        {
+            `INSERT INTO voting_session (room_id, number, active, official, started_at)
+             SELECT r.id, 1, true, true, NOW() FROM room r
+             WHERE NOT EXISTS (SELECT 1 FROM voting_session s WHERE s.room_id = r.id)`,
+            "voting_session inicial para las salas existentes",
+        },
+        {
+            `UPDATE vote v SET session_id = s.id
+             FROM proposal p, voting_session s
+             WHERE v.session_id IS NULL
+               AND p.id = COALESCE(v.proposal_id, (SELECT o.proposal_id FROM option o WHERE o.id = v.option_id))
+               AND s.room_id = p.room_id AND s.number = 1`,
+            "session_id de los votos existentes",
+        },
+        {
+            `UPDATE vote_participation vp SET session_id = s.id
+             FROM proposal p, voting_session s
+             WHERE vp.session_id = 0 AND p.id = vp.proposal_id AND s.room_id = p.room_id AND s.number = 1`,
+            "session_id de las participaciones existentes",
+        },
+        {
+            `ALTER TABLE vote_participation DROP COLUMN IF EXISTS id`,
+            "vote_participation sin id secuencial",
+        },
+        {
+            `ALTER TABLE proposal_outcome ADD CONSTRAINT fk_proposal FOREIGN KEY (proposal_id) REFERENCES proposal(id) ON DELETE CASCADE`,
+            "fk_proposal on proposal_outcome",
+        },
+        {
+            `DO $$
+             BEGIN
+                 IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'proposal' AND column_name = 'outcome') THEN
+                     ALTER TABLE proposal ADD COLUMN IF NOT EXISTS outcome_session_id BIGINT NULL;
+                     INSERT INTO proposal_outcome (proposal_id, session_id, outcome, outcome_rule, winner_option_id, outcome_base, outcome_threshold, decided_at)
+                     SELECT p.id, COALESCE(p.outcome_session_id, s.id), p.outcome, p.decision_rule, p.winner_option_id,
+                            p.outcome_base, p.outcome_threshold, COALESCE(p.decided_at, NOW())
+                     FROM proposal p
+                     JOIN voting_session s ON s.room_id = p.room_id AND s.number = 1
+                     WHERE p.outcome IS NOT NULL
+                     ON CONFLICT (proposal_id, session_id) DO NOTHING;
+                 END IF;
+             END $$`,
+            "proposal_outcome de los resultados existentes",
+        },
+        {
+            `ALTER TABLE proposal
+                 DROP COLUMN IF EXISTS outcome,
+                 DROP COLUMN IF EXISTS winner_option_id,
+                 DROP COLUMN IF EXISTS outcome_base,
+                 DROP COLUMN IF EXISTS outcome_threshold,
+                 DROP COLUMN IF EXISTS decided_at,
+                 DROP COLUMN IF EXISTS outcome_session_id`,
+            "proposal sin las columnas de resultado, ahora en proposal_outcome",
+        },
         {
             `CREATE UNIQUE INDEX IF NOT EXISTS value_proposal_idx ON option(value, proposal_id)`,
             "value_proposal_idx unique index on option(value, proposal_id)",
@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"suffgo/internal/delegations/domain"
+	de "suffgo/internal/delegations/domain/errors"
+	rd "suffgo/internal/rooms/domain"
+	re "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type (
+	CreateUsecase struct {
+		repository domain.DelegationRepository
+		roomRepo   rd.RoomRepository
+	}
+)
+
+func NewCreateUsecase(repository domain.DelegationRepository, roomRepo rd.RoomRepository) *CreateUsecase {
+	return &CreateUsecase{
+		repository: repository,
+		roomRepo:   roomRepo,
+	}
+}
+
+func (s *CreateUsecase) Execute(delegation domain.Delegation) (*domain.Delegation, error) {
+	room, err := s.roomRepo.GetByID(delegation.RoomID())
+	if err != nil {
+		return nil, err
+	}
+
+	if room == nil {
+		return nil, re.ErrRoomNotFound
+	}
+
+	if !room.State().NotStarted() {
+		return nil, de.ErrRoomNotEditable
+	}
+
+	if delegation.DelegatorID().Id == delegation.DelegateID().Id {
+		return nil, de.ErrSelfDelegation
+	}
+
+	//ambos deben estar habilitados en la sala
+	for _, userID := range []sv.ID{delegation.DelegatorID(), delegation.DelegateID()} {
+		can, err := s.roomRepo.UserInWhitelist(room.ID(), userID)
+		if err != nil {
+			return nil, err
+		}
+		if !can {
+			return nil, de.ErrNotWhitelisted
+		}
+	}
+
+	already, err := s.repository.GetByDelegator(room.ID(), delegation.DelegatorID())
+	if err != nil {
+		return nil, err
+	}
+	if already != nil {
+		return nil, de.ErrAlreadyDelegated
+	}
+
+	//sin cadenas (ni ciclos): el delegado no puede haber delegado y el delegador no puede representar a nadie
+	delegateDelegated, err := s.repository.GetByDelegator(room.ID(), delegation.DelegateID())
+	if err != nil {
+		return nil, err
+	}
+	if delegateDelegated != nil {
+		return nil, de.ErrDelegationChain
+	}
+
+	represented, err := s.repository.GetByDelegate(room.ID(), delegation.DelegatorID())
+	if err != nil {
+		return nil, err
+	}
+	if len(represented) > 0 {
+		return nil, de.ErrDelegationChain
+	}
+
+	return s.repository.Save(delegation)
+}
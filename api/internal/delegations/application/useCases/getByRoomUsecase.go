@@ -0,0 +1,22 @@
+package usecases
+
+import (
+	"suffgo/internal/delegations/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type (
+	GetByRoomUsecase struct {
+		repository domain.DelegationRepository
+	}
+)
+
+func NewGetByRoomUsecase(repository domain.DelegationRepository) *GetByRoomUsecase {
+	return &GetByRoomUsecase{
+		repository: repository,
+	}
+}
+
+func (s *GetByRoomUsecase) Execute(roomID sv.ID) ([]domain.Delegation, error) {
+	return s.repository.GetByRoom(roomID)
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"suffgo/internal/delegations/domain"
+	de "suffgo/internal/delegations/domain/errors"
+	rd "suffgo/internal/rooms/domain"
+	re "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type (
+	RevokeUsecase struct {
+		repository domain.DelegationRepository
+		roomRepo   rd.RoomRepository
+	}
+)
+
+func NewRevokeUsecase(repository domain.DelegationRepository, roomRepo rd.RoomRepository) *RevokeUsecase {
+	return &RevokeUsecase{
+		repository: repository,
+		roomRepo:   roomRepo,
+	}
+}
+
+// la puede revocar el delegador o el admin de la sala, solo antes de que la sala este online
+func (s *RevokeUsecase) Execute(id sv.ID, requester sv.ID) error {
+	delegation, err := s.repository.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	room, err := s.roomRepo.GetByID(delegation.RoomID())
+	if err != nil {
+		return err
+	}
+
+	if room == nil {
+		return re.ErrRoomNotFound
+	}
+
+	if requester.Id != delegation.DelegatorID().Id && requester.Id != room.AdminID().Id {
+		return re.ErrUserNotAdmin
+	}
+
+	if !room.State().NotStarted() {
+		return de.ErrRoomNotEditable
+	}
+
+	return s.repository.Delete(id)
+}
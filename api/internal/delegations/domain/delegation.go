@@ -0,0 +1,57 @@
+package domain
+
+import (
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type (
+	// voto por poder: el delegador cede su voto a otro miembro de la whitelist de la sala
+	Delegation struct {
+		id          *sv.ID
+		roomID      sv.ID
+		delegatorID sv.ID
+		delegateID  sv.ID
+	}
+
+	DelegationDTO struct {
+		ID          uint `json:"id"`
+		RoomID      uint `json:"room_id"`
+		DelegatorID uint `json:"delegator_id"`
+		DelegateID  uint `json:"delegate_id"`
+	}
+
+	DelegationCreateRequest struct {
+		RoomID     uint `json:"room_id"`
+		DelegateID uint `json:"delegate_id"`
+	}
+)
+
+func NewDelegation(
+	id *sv.ID,
+	roomID *sv.ID,
+	delegatorID *sv.ID,
+	delegateID *sv.ID,
+) *Delegation {
+	return &Delegation{
+		id:          id,
+		roomID:      *roomID,
+		delegatorID: *delegatorID,
+		delegateID:  *delegateID,
+	}
+}
+
+func (d *Delegation) ID() sv.ID {
+	return *d.id
+}
+
+func (d *Delegation) RoomID() sv.ID {
+	return d.roomID
+}
+
+func (d *Delegation) DelegatorID() sv.ID {
+	return d.delegatorID
+}
+
+func (d *Delegation) DelegateID() sv.ID {
+	return d.delegateID
+}
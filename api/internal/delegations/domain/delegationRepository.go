@@ -0,0 +1,14 @@
+package domain
+
+import (
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type DelegationRepository interface {
+	GetByID(id sv.ID) (*Delegation, error)
+	GetByRoom(roomID sv.ID) ([]Delegation, error)
+	GetByDelegator(roomID sv.ID, delegatorID sv.ID) (*Delegation, error)
+	GetByDelegate(roomID sv.ID, delegateID sv.ID) ([]Delegation, error)
+	Save(delegation Delegation) (*Delegation, error)
+	Delete(id sv.ID) error
+}
@@ -0,0 +1,9 @@
+package errors
+
+type alreadyDelegatedConst string
+
+const ErrAlreadyDelegated alreadyDelegatedConst = "the user already delegated the vote in this room."
+
+func (e alreadyDelegatedConst) Error() string {
+	return string(e)
+}
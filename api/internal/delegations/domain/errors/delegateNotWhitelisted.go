@@ -0,0 +1,9 @@
+package errors
+
+type delegateNotWhitelistedConst string
+
+const ErrNotWhitelisted delegateNotWhitelistedConst = "both users must be in the room whitelist."
+
+func (e delegateNotWhitelistedConst) Error() string {
+	return string(e)
+}
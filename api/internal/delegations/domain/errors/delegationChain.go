@@ -0,0 +1,9 @@
+package errors
+
+type delegationChainConst string
+
+const ErrDelegationChain delegationChainConst = "delegation chains are not allowed."
+
+func (e delegationChainConst) Error() string {
+	return string(e)
+}
@@ -0,0 +1,9 @@
+package errors
+
+type delegationNotFoundConst string
+
+const ErrDelegationNotFound delegationNotFoundConst = "delegation not found."
+
+func (e delegationNotFoundConst) Error() string {
+	return string(e)
+}
@@ -0,0 +1,9 @@
+package errors
+
+type roomNotEditableConst string
+
+const ErrRoomNotEditable roomNotEditableConst = "delegations can only be changed before the room goes online."
+
+func (e roomNotEditableConst) Error() string {
+	return string(e)
+}
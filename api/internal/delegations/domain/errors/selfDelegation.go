@@ -0,0 +1,9 @@
+package errors
+
+type selfDelegationConst string
+
+const ErrSelfDelegation selfDelegationConst = "a user can't delegate the vote to himself."
+
+func (e selfDelegationConst) Error() string {
+	return string(e)
+}
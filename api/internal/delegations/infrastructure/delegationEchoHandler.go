@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	u "suffgo/internal/delegations/application/useCases"
+	d "suffgo/internal/delegations/domain"
+	derrors "suffgo/internal/delegations/domain/errors"
+	re "suffgo/internal/rooms/domain/errors"
+	rh "suffgo/internal/rooms/infrastructure"
+	se "suffgo/internal/shared/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DelegationEchoHandler struct {
+	CreateDelegationUsecase    *u.CreateUsecase
+	RevokeDelegationUsecase    *u.RevokeUsecase
+	GetDelegationByRoomUsecase *u.GetByRoomUsecase
+}
+
+func NewDelegationEchoHandler(
+	createUC *u.CreateUsecase,
+	revokeUC *u.RevokeUsecase,
+	getByRoomUC *u.GetByRoomUsecase,
+) *DelegationEchoHandler {
+	return &DelegationEchoHandler{
+		CreateDelegationUsecase:    createUC,
+		RevokeDelegationUsecase:    revokeUC,
+		GetDelegationByRoomUsecase: getByRoomUC,
+	}
+}
+
+// el usuario logueado delega su voto en delegate_id
+func (h *DelegationEchoHandler) CreateDelegation(c echo.Context) error {
+	var req d.DelegationCreateRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	delegatorID, err := rh.GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	roomID, err := sv.NewID(req.RoomID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	delegateID, err := sv.NewID(req.DelegateID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	delegation := d.NewDelegation(nil, roomID, delegatorID, delegateID)
+
+	created, err := h.CreateDelegationUsecase.Execute(*delegation)
+	if err != nil {
+		if errors.Is(err, re.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, derrors.ErrNotWhitelisted) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, derrors.ErrAlreadyDelegated) || errors.Is(err, derrors.ErrDelegationChain) || errors.Is(err, derrors.ErrRoomNotEditable) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, derrors.ErrSelfDelegation) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	delegationDTO := d.DelegationDTO{
+		ID:          created.ID().Id,
+		RoomID:      created.RoomID().Id,
+		DelegatorID: created.DelegatorID().Id,
+		DelegateID:  created.DelegateID().Id,
+	}
+
+	response := map[string]interface{}{
+		"success":    "delegación creada exitosamente",
+		"delegation": delegationDTO,
+	}
+
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (h *DelegationEchoHandler) RevokeDelegation(c echo.Context) error {
+	idParam := c.Param("id")
+	idInput, err := strconv.ParseInt(idParam, 10, 64)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	id, _ := sv.NewID(uint(idInput))
+
+	currentUser, err := rh.GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.RevokeDelegationUsecase.Execute(*id, *currentUser)
+	if err != nil {
+		if errors.Is(err, derrors.ErrDelegationNotFound) || errors.Is(err, re.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, re.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, derrors.ErrRoomNotEditable) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"success": "Delegation revoked succesfully"})
+}
+
+func (h *DelegationEchoHandler) GetDelegationsByRoom(c echo.Context) error {
+	roomID, err := sv.NewID(c.Param("room_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	delegations, err := h.GetDelegationByRoomUsecase.Execute(*roomID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	delegationsDTO := []d.DelegationDTO{}
+	for _, delegation := range delegations {
+		delegationsDTO = append(delegationsDTO, d.DelegationDTO{
+			ID:          delegation.ID().Id,
+			RoomID:      delegation.RoomID().Id,
+			DelegatorID: delegation.DelegatorID().Id,
+			DelegateID:  delegation.DelegateID().Id,
+		})
+	}
+
+	return c.JSON(http.StatusOK, delegationsDTO)
+}
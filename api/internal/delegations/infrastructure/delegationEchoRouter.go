@@ -0,0 +1,16 @@
+package infrastructure
+
+import (
+	userInfr "suffgo/internal/users/infrastructure"
+
+	"github.com/labstack/echo/v4"
+)
+
+func InitializeDelegationEchoRouter(e *echo.Echo, handler *DelegationEchoHandler) {
+	delegationGroup := e.Group("/v1/delegations")
+
+	delegationGroup.Use(userInfr.AuthMiddleware)
+	delegationGroup.POST("", handler.CreateDelegation)
+	delegationGroup.DELETE("/:id", handler.RevokeDelegation)
+	delegationGroup.GET("/byRoom/:room_id", handler.GetDelegationsByRoom)
+}
@@ -0,0 +1,124 @@
+package infrastructure
+
+import (
+	"suffgo/cmd/database"
+	d "suffgo/internal/delegations/domain"
+	de "suffgo/internal/delegations/domain/errors"
+	"suffgo/internal/delegations/infrastructure/mappers"
+	m "suffgo/internal/delegations/infrastructure/models"
+	se "suffgo/internal/shared/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type DelegationXormRepository struct {
+	db database.Database
+}
+
+func NewDelegationXormRepository(db database.Database) *DelegationXormRepository {
+	return &DelegationXormRepository{
+		db: db,
+	}
+}
+
+func (s *DelegationXormRepository) GetByID(id sv.ID) (*d.Delegation, error) {
+	delegationModel := new(m.Delegation)
+	has, err := s.db.GetDb().ID(id.Id).Get(delegationModel)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, de.ErrDelegationNotFound
+	}
+
+	delegationEnt, err := mappers.ModelToDomain(delegationModel)
+	if err != nil {
+		return nil, se.ErrDataMap
+	}
+
+	return delegationEnt, nil
+}
+
+func (s *DelegationXormRepository) GetByRoom(roomID sv.ID) ([]d.Delegation, error) {
+	var delegations []m.Delegation
+	err := s.db.GetDb().Where("room_id = ?", roomID.Id).Find(&delegations)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomain(delegations)
+}
+
+// nil si el usuario no delego su voto en la sala
+func (s *DelegationXormRepository) GetByDelegator(roomID sv.ID, delegatorID sv.ID) (*d.Delegation, error) {
+	delegationModel := new(m.Delegation)
+	has, err := s.db.GetDb().Where("room_id = ? AND delegator_id = ?", roomID.Id, delegatorID.Id).Get(delegationModel)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+
+	delegationEnt, err := mappers.ModelToDomain(delegationModel)
+	if err != nil {
+		return nil, se.ErrDataMap
+	}
+
+	return delegationEnt, nil
+}
+
+func (s *DelegationXormRepository) GetByDelegate(roomID sv.ID, delegateID sv.ID) ([]d.Delegation, error) {
+	var delegations []m.Delegation
+	err := s.db.GetDb().Where("room_id = ? AND delegate_id = ?", roomID.Id, delegateID.Id).Find(&delegations)
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomain(delegations)
+}
+
+func (s *DelegationXormRepository) Save(delegation d.Delegation) (*d.Delegation, error) {
+	delegationModel := &m.Delegation{
+		RoomID:      delegation.RoomID().Id,
+		DelegatorID: delegation.DelegatorID().Id,
+		DelegateID:  delegation.DelegateID().Id,
+	}
+
+	_, err := s.db.GetDb().Insert(delegationModel)
+	if err != nil {
+		return nil, err
+	}
+
+	delegationEnt, err := mappers.ModelToDomain(delegationModel)
+	if err != nil {
+		return nil, se.ErrDataMap
+	}
+
+	return delegationEnt, nil
+}
+
+func (s *DelegationXormRepository) Delete(id sv.ID) error {
+	affected, err := s.db.GetDb().ID(id.Id).Delete(&m.Delegation{})
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return de.ErrDelegationNotFound
+	}
+
+	return nil
+}
+
+func toDomain(delegations []m.Delegation) ([]d.Delegation, error) {
+	var delegationsDomain []d.Delegation
+	for _, delegation := range delegations {
+		delegationDomain, err := mappers.ModelToDomain(&delegation)
+		if err != nil {
+			return nil, se.ErrDataMap
+		}
+
+		delegationsDomain = append(delegationsDomain, *delegationDomain)
+	}
+	return delegationsDomain, nil
+}
@@ -0,0 +1,40 @@
+package mappers
+
+import (
+	"suffgo/internal/delegations/domain"
+	m "suffgo/internal/delegations/infrastructure/models"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+func DomainToModel(delegation *domain.Delegation) *m.Delegation {
+	return &m.Delegation{
+		ID:          delegation.ID().Id,
+		RoomID:      delegation.RoomID().Id,
+		DelegatorID: delegation.DelegatorID().Id,
+		DelegateID:  delegation.DelegateID().Id,
+	}
+}
+
+func ModelToDomain(delegationModel *m.Delegation) (*domain.Delegation, error) {
+	id, err := sv.NewID(delegationModel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID, err := sv.NewID(delegationModel.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	delegatorID, err := sv.NewID(delegationModel.DelegatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	delegateID, err := sv.NewID(delegationModel.DelegateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewDelegation(id, roomID, delegatorID, delegateID), nil
+}
@@ -0,0 +1,8 @@
+package models
+
+type Delegation struct {
+	ID          uint `xorm:"'id' pk autoincr"`
+	RoomID      uint `xorm:"'room_id' index not null unique(room_delegator_idx)"`
+	DelegatorID uint `xorm:"'delegator_id' not null unique(room_delegator_idx)"` // quien cede el voto, una vez por sala
+	DelegateID  uint `xorm:"'delegate_id' index not null"`                      // quien vota en su nombre
+}
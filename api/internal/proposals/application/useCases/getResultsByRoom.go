@@ -1,28 +1,190 @@
 package usecases
 
 import (
+	"errors"
 	"suffgo/internal/proposals/domain"
+	v "suffgo/internal/proposals/domain/valueObjects"
+	rd "suffgo/internal/rooms/domain"
+	re "suffgo/internal/rooms/domain/errors"
+	srd "suffgo/internal/settingsRoom/domain"
+	srerr "suffgo/internal/settingsRoom/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
 )
 
 type (
+	// configuracion de la sala que afecta los resultados
+	resultRules struct {
+		required      int  //quorum de votos
+		secret        bool //no se informa quien voto que
+		abstainQuorum bool //abstenciones y votos en blanco cuentan para el quorum
+	}
+
 	GetResultsByRoomUsecase struct {
 		getResultsByRoomRepository domain.ProposalRepository
+		roomRepository             rd.RoomRepository
+		settingRoomRepository      srd.SettingRoomRepository
 	}
 )
 
-func NewGetResultsByRoomUsecase(repository domain.ProposalRepository) *GetResultsByRoomUsecase {
+func NewGetResultsByRoomUsecase(repository domain.ProposalRepository, roomRepo rd.RoomRepository, srRepo srd.SettingRoomRepository) *GetResultsByRoomUsecase {
 	return &GetResultsByRoomUsecase{
 		getResultsByRoomRepository: repository,
+		roomRepository:             roomRepo,
+		settingRoomRepository:      srRepo,
 	}
 }
 
-func (s *GetResultsByRoomUsecase) Execute(roomId *sv.ID) ([]domain.ProposalResults, error) {
-	proposal, err := s.getResultsByRoomRepository.GetResultsByRoom(*roomId)
+// sin sesion se usan los votos de la sesion oficial de la sala
+func (s *GetResultsByRoomUsecase) Execute(roomId *sv.ID, sessionId *sv.ID) ([]domain.ProposalResults, error) {
+	session, err := s.resultsSession(*roomId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, err := s.getResultsByRoomRepository.GetResultsByRoom(*roomId, session)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.roomRules(*roomId)
+	if err != nil {
+		return nil, err
+	}
 
+	eligible, err := s.roomRepository.WhitelistTotalWeight(*roomId)
 	if err != nil {
 		return nil, err
 	}
 
+	for i := range proposal {
+		//con re-votaciones cada ronda se cuenta por separado y la propuesta muestra la ultima
+		if len(proposal[i].Rounds) > 0 {
+			rounds := proposal[i].Rounds
+			for j := range rounds {
+				countResults(&rounds[j], rules, eligible)
+			}
+			proposal[i] = rounds[len(rounds)-1]
+			proposal[i].Rounds = rounds
+			continue
+		}
+		countResults(&proposal[i], rules, eligible)
+	}
+
 	return proposal, nil
 }
+
+// votos emitidos, quorum y resultado de la propuesta (o de una de sus rondas)
+func countResults(results *domain.ProposalResults, rules resultRules, eligible int) {
+	cast := 0
+	if results.BallotType == v.BallotRanked {
+		//en las ranked cada votante tiene una fila por opcion rankeada
+		ballots := results.Ballots()
+		cast = len(ballots)
+
+		var options []uint
+		for _, option := range results.Options {
+			options = append(options, option.OptionId)
+		}
+		runoff := domain.InstantRunoff(options, ballots)
+		results.Runoff = &runoff
+	} else if results.BallotType == v.BallotApproval || results.BallotType == v.BallotMulti {
+		//cada votante cuenta una vez aunque haya elegido varias opciones
+		cast = len(results.Ballots())
+	} else {
+		for j := range results.Options {
+			cast += len(results.Options[j].Votes)
+		}
+	}
+
+	//los totales por opcion suman el peso de cada voto
+	for j := range results.Options {
+		results.Options[j].Approvals = 0
+		for _, vote := range results.Options[j].Votes {
+			results.Options[j].Approvals += vote.Weight
+		}
+	}
+
+	//abstenciones y votos en blanco son boletas emitidas, el quorum depende de la configuracion
+	nonOption := results.Abstain.Count + results.Blank.Count
+	results.VotesCast = cast + nonOption
+	results.Required = rules.required
+	if rules.abstainQuorum {
+		results.Valid = cast+nonOption >= rules.required
+	} else {
+		results.Valid = cast >= rules.required
+	}
+
+	//el resultado persistido al cerrar la propuesta tiene prioridad sobre el recalculo
+	if results.Outcome == nil {
+		ballotType := v.BallotType{BallotType: results.BallotType}
+
+		var approvals []domain.OptionTally
+		for _, option := range results.Options {
+			approvals = append(approvals, domain.OptionTally{OptionId: option.OptionId, Votes: option.Approvals})
+		}
+
+		tally := domain.NewTally(ballotType, results.Ballots(), approvals, results.Runoff)
+		tally.Cast += results.Blank.Weight
+		tally.Abstentions = results.Abstain.Weight
+		tally.Eligible = eligible
+		tally.QuorumMet = results.Valid
+
+		outcome := domain.Decide(results.DecisionRule, tally)
+		results.Outcome = &outcome
+	}
+
+	//votacion secreta: solo se informan los totales por opcion
+	if rules.secret {
+		results.Secret = true
+		for j := range results.Options {
+			results.Options[j].Votes = []domain.VotesResults{}
+		}
+	}
+}
+
+func (s *GetResultsByRoomUsecase) resultsSession(roomId sv.ID, sessionId *sv.ID) (sv.ID, error) {
+	sessions, err := s.roomRepository.GetSessions(roomId)
+	if err != nil {
+		return sv.ID{}, err
+	}
+
+	for _, session := range sessions {
+		if sessionId != nil && session.ID == sessionId.Id {
+			return *sessionId, nil
+		}
+		if sessionId == nil && session.Official {
+			return sv.ID{Id: session.ID}, nil
+		}
+	}
+
+	if sessionId != nil {
+		return sv.ID{}, re.ErrSessionNotFound
+	}
+
+	//la sala todavia no tuvo votaciones
+	return sv.ID{}, nil
+}
+
+// quorum requerido, si la sala vota en secreto y si las abstenciones cuentan para el quorum
+func (s *GetResultsByRoomUsecase) roomRules(roomId sv.ID) (resultRules, error) {
+	settingRoom, err := s.settingRoomRepository.GetByRoom(roomId)
+	if err != nil {
+		//sin configuracion no hay quorum ni voto secreto
+		if errors.Is(err, srerr.SettingRoomNotFoundError) {
+			return resultRules{abstainQuorum: true}, nil
+		}
+		return resultRules{}, err
+	}
+
+	whitelistSize, err := s.roomRepository.CountWhitelist(roomId)
+	if err != nil {
+		return resultRules{}, err
+	}
+
+	return resultRules{
+		required:      settingRoom.Quorum().Required(whitelistSize),
+		secret:        settingRoom.SecretBallot().Enabled(),
+		abstainQuorum: settingRoom.AbstainQuorum().Enabled(),
+	}, nil
+}
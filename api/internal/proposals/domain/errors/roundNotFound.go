@@ -0,0 +1,9 @@
+package errors
+
+type roundNotFoundConst string
+
+const ErrRoundNotFound roundNotFoundConst = "proposal round not found."
+
+func (p roundNotFoundConst) Error() string {
+	return string(p)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"sort"
+	v "suffgo/internal/proposals/domain/valueObjects"
+)
+
+const (
+	OutcomeApproved = "approved"
+	OutcomeRejected = "rejected"
+	OutcomeTied     = "tied"
+	OutcomeNoQuorum = "no_quorum"
+)
+
+type (
+	Outcome struct {
+		Outcome   string `json:"outcome"`
+		Winner    *uint  `json:"winner,omitempty"` //opcion aprobada
+		Rule      string `json:"rule"`
+		Base      int    `json:"base"`      //peso sobre el que se calcula el umbral
+		Threshold int    `json:"threshold"` //peso necesario para aprobar
+	}
+
+	// totales de una propuesta ya ponderados por peso
+	Tally struct {
+		Tallies     []OptionTally
+		Cast        int //peso de las boletas emitidas
+		Abstentions int //peso de las abstenciones
+		Eligible    int //peso total de la whitelist
+		QuorumMet   bool
+	}
+)
+
+// aplica la regla de decision de la propuesta sobre los totales
+func Decide(rule v.DecisionRule, t Tally) Outcome {
+	outcome := Outcome{Rule: rule.Rule}
+
+	if !t.QuorumMet {
+		outcome.Outcome = OutcomeNoQuorum
+		return outcome
+	}
+
+	base := t.Cast
+	if rule.Abstentions == v.AbstentionsAgainst {
+		base += t.Abstentions
+	}
+	//en salas sin whitelist la mayoria absoluta se calcula sobre los votos emitidos
+	if rule.Rule == v.RuleAbsoluteMajority && t.Eligible > 0 {
+		base = t.Eligible
+	}
+
+	outcome.Base = base
+	outcome.Threshold = rule.Threshold(base)
+
+	tallies := append([]OptionTally(nil), t.Tallies...)
+	sort.SliceStable(tallies, func(i, j int) bool { return tallies[i].Votes > tallies[j].Votes })
+
+	if len(tallies) == 0 || tallies[0].Votes == 0 {
+		outcome.Outcome = OutcomeRejected
+		return outcome
+	}
+
+	if len(tallies) > 1 && tallies[0].Votes == tallies[1].Votes {
+		outcome.Outcome = OutcomeTied
+		return outcome
+	}
+
+	if tallies[0].Votes < outcome.Threshold {
+		outcome.Outcome = OutcomeRejected
+		return outcome
+	}
+
+	winner := tallies[0].OptionId
+	outcome.Outcome = OutcomeApproved
+	outcome.Winner = &winner
+	return outcome
+}
+
+// totales segun el tipo de boleta. En las ranked se toma la ultima ronda de la segunda vuelta
+func NewTally(ballotType v.BallotType, ballots []Ballot, approvals []OptionTally, runoff *RunoffResult) Tally {
+	var t Tally
+
+	if ballotType.IsRanked() && runoff != nil && len(runoff.Rounds) > 0 {
+		last := runoff.Rounds[len(runoff.Rounds)-1]
+		t.Tallies = last.Tallies
+		for _, tally := range last.Tallies {
+			t.Cast += tally.Votes
+		}
+		return t
+	}
+
+	t.Tallies = approvals
+	for _, ballot := range ballots {
+		t.Cast += ballot.weight()
+	}
+	return t
+}
@@ -7,11 +7,13 @@ import (
 
 type (
 	Proposal struct {
-		id          *sv.ID
-		archive     *v.Archive
-		title       v.Title
-		description *v.Description
-		roomID      sv.ID
+		id           *sv.ID
+		archive      *v.Archive
+		title        v.Title
+		description  *v.Description
+		ballotType   v.BallotType
+		decisionRule v.DecisionRule
+		roomID       sv.ID
 	}
 
 	ProposalDTO struct {
@@ -19,6 +21,11 @@ type (
 		Archive     string  `json:"archive"`
 		Title       string  `json:"title"`
 		Description *string `json:"description"`
+		BallotType  string  `json:"ballot_type"`
+		MinSelect   int     `json:"min_selections"`
+		MaxSelect   int     `json:"max_selections"`
+		Rule        string  `json:"decision_rule"`
+		Abstentions string  `json:"abstentions"`
 		RoomID      uint    `json:"room_id"`
 	}
 
@@ -26,6 +33,11 @@ type (
 		Archive       string  `json:"archive"`
 		Title         string  `json:"title"`
 		Description   *string `json:"description"`
+		BallotType    string  `json:"ballot_type"`
+		MinSelect     int     `json:"min_selections"`
+		MaxSelect     int     `json:"max_selections"`
+		Rule          string  `json:"decision_rule"`
+		Abstentions   string  `json:"abstentions"`
 		RoomID        uint    `json:"room_id"`
 		UserCreatorID uint    `json:"user_creator_id"`
 	}
@@ -34,20 +46,45 @@ type (
 		Archive     string  `json:"archive"`
 		Title       string  `json:"title"`
 		Description *string `json:"description"`
+		BallotType  string  `json:"ballot_type"`
+		MinSelect   int     `json:"min_selections"`
+		MaxSelect   int     `json:"max_selections"`
+		Rule        string  `json:"decision_rule"`
+		Abstentions string  `json:"abstentions"`
 	}
 
 	ProposalResults struct {
-		ProposalId          uint            `json:"id"`
-		ProposalTitle       string          `json:"title"`
-		ProposalDescription string          `json:"description"`
-		RoomID              uint            `json:"room_id"`
-		Options             []OptionResults `json:"options"`
+		ProposalId          uint              `json:"id"`
+		ProposalTitle       string            `json:"title"`
+		ProposalDescription string            `json:"description"`
+		RoomID              uint              `json:"room_id"`
+		SessionID           uint              `json:"session_id"` //sesion de votacion de los votos
+		BallotType          string            `json:"ballot_type"`
+		Options             []OptionResults   `json:"options"`
+		VotesCast           int               `json:"votes_cast"`
+		Required            int               `json:"required"`
+		Valid               bool              `json:"valid"`            //false si no se alcanzo el quorum
+		Secret              bool              `json:"secret"`           //true si solo se informan totales por opcion
+		Runoff              *RunoffResult     `json:"runoff,omitempty"` //solo en propuestas ranked
+		Abstain             ChoiceResults     `json:"abstain"`
+		Blank               ChoiceResults     `json:"blank"`
+		DecisionRule        v.DecisionRule    `json:"-"`
+		Outcome             *Outcome          `json:"outcome"`
+		Round               int               `json:"round"`            //ronda de los resultados, la ultima votada
+		Rounds              []ProposalResults `json:"rounds,omitempty"` //todas las rondas en orden, solo si hubo re-votaciones
+	}
+
+	// abstenciones o votos en blanco de una propuesta
+	ChoiceResults struct {
+		Count  int `json:"count"`
+		Weight int `json:"weight"`
 	}
 
 	OptionResults struct {
 		OptionId    uint           `json:"option_Id"`
 		OptionValue string         `json:"option_value"`
 		Votes       []VotesResults `json:"votes"`
+		Approvals   int            `json:"approvals"` //suma de los pesos de los votantes que eligieron la opcion
 	}
 
 	VotesResults struct {
@@ -55,6 +92,11 @@ type (
 		UserId    uint   `json:"user_id"`
 		Username  string `json:"username"`
 		UserImage string `json:"user_image"`
+		Rank      int    `json:"rank,omitempty"` //posicion de la opcion en la boleta ranked
+		BallotId  string `json:"-"`              //boletas secretas, nunca se expone
+		Weight    int    `json:"weight"`
+		ProxyId   uint   `json:"proxy_id,omitempty"` //delegado que voto en nombre del usuario
+		Round     int    `json:"-"`
 	}
 )
 
@@ -63,14 +105,18 @@ func NewProposal(
 	archive *v.Archive,
 	title v.Title,
 	description *v.Description,
+	ballotType v.BallotType,
+	decisionRule v.DecisionRule,
 	roomID *sv.ID,
 ) *Proposal {
 	return &Proposal{
-		id:          id,
-		archive:     archive,
-		title:       title,
-		description: description,
-		roomID:      *roomID,
+		id:           id,
+		archive:      archive,
+		title:        title,
+		description:  description,
+		ballotType:   ballotType,
+		decisionRule: decisionRule,
+		roomID:       *roomID,
 	}
 }
 
@@ -90,6 +136,14 @@ func (p *Proposal) Description() *v.Description {
 	return p.description
 }
 
+func (p *Proposal) BallotType() v.BallotType {
+	return p.ballotType
+}
+
+func (p *Proposal) DecisionRule() v.DecisionRule {
+	return p.decisionRule
+}
+
 func (p *Proposal) RoomID() sv.ID {
 	return p.roomID
 }
@@ -11,5 +11,8 @@ type ProposalRepository interface {
 	Save(proposal Proposal) (*Proposal, error)
 	Update(proposal *Proposal) (*Proposal, error)
 	GetByRoom(roomId sv.ID) ([]Proposal, error)
-	GetResultsByRoom(roomId sv.ID) ([]ProposalResults, error)
+	GetResultsByRoom(roomId sv.ID, sessionId sv.ID) ([]ProposalResults, error)
+	SaveOutcome(proposalID sv.ID, sessionID sv.ID, round int, outcome Outcome) error
+	SaveRound(round Round) (*Round, error)
+	GetRounds(proposalID sv.ID, sessionID sv.ID) ([]Round, error)
 }
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// re-votacion de una propuesta dentro de una sesion de la sala. La ronda 1 es la votacion
+// original de la propuesta, las siguientes se guardan aparte
+type Round struct {
+	ID         uint      `json:"id"`
+	ProposalID uint      `json:"proposal_id"`
+	SessionID  uint      `json:"session_id"`
+	Number     int       `json:"number"`  //2 para la primera re-votacion
+	Options    []uint    `json:"options"` //opciones que se votan en la ronda, vacio si se votan todas
+	Reason     string    `json:"reason"`
+	Outcome    *Outcome  `json:"outcome,omitempty"` //nil hasta que se muestran los resultados de la ronda
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// las n opciones con mas votos. Las empatadas con la ultima posicion tambien pasan, asi el
+// desempate no depende del orden de las opciones
+func TopOptions(tallies []OptionTally, n int) []uint {
+	sorted := append([]OptionTally(nil), tallies...)
+	if n <= 0 || n > len(sorted) {
+		n = len(sorted)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Votes > sorted[j].Votes })
+
+	var top []uint
+	for i, tally := range sorted {
+		if i >= n && tally.Votes < sorted[n-1].Votes {
+			break
+		}
+		top = append(top, tally.OptionId)
+	}
+	return top
+}
+
+// resultados de una sola ronda: solo las opciones de la ronda y los votos emitidos en ella
+func (p ProposalResults) ForRound(number int, options []uint) ProposalResults {
+	allowed := make(map[uint]bool, len(options))
+	for _, option := range options {
+		allowed[option] = true
+	}
+
+	round := p
+	round.Round = number
+	round.Rounds = nil
+	round.Options = []OptionResults{}
+	for _, option := range p.Options {
+		if len(allowed) > 0 && !allowed[option.OptionId] {
+			continue
+		}
+
+		votes := []VotesResults{}
+		for _, vote := range option.Votes {
+			if vote.Round == number {
+				votes = append(votes, vote)
+			}
+		}
+		option.Votes = votes
+		round.Options = append(round.Options, option)
+	}
+	return round
+}
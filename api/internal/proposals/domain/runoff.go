@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	TieBreakPreviousRound = "previous_round" //se elimina la opcion con menos votos en la ronda anterior mas cercana que los distinga
+	TieBreakFirstChoice   = "first_choice"   //se elimina la opcion con menos primeras preferencias
+	TieBreakOptionOrder   = "option_order"   //empate total: se elimina la opcion creada ultima
+)
+
+type (
+	// boleta: ids de opciones ordenados por preferencia y el peso del votante
+	Ballot struct {
+		Options []uint `json:"options"`
+		Weight  int    `json:"weight"`
+	}
+
+	RunoffResult struct {
+		Rounds []RunoffRound `json:"rounds"`
+		Winner *uint         `json:"winner"` //nil si no hubo boletas validas
+	}
+
+	RunoffRound struct {
+		Round      int           `json:"round"`
+		Tallies    []OptionTally `json:"tallies"`
+		Exhausted  int           `json:"exhausted"` //peso de las boletas sin opciones activas
+		Majority   int           `json:"majority"`
+		Eliminated *uint         `json:"eliminated,omitempty"`
+		TieBreak   string        `json:"tie_break,omitempty"`
+		Tied       []uint        `json:"tied,omitempty"`
+	}
+
+	OptionTally struct {
+		OptionId uint `json:"option_id"`
+		Votes    int  `json:"votes"`
+	}
+)
+
+// segunda vuelta instantanea: en cada ronda cada boleta suma su peso a su opcion activa mejor rankeada.
+// Gana la opcion con mayoria absoluta de las boletas no agotadas, si no se elimina la de menos votos y se repite
+func InstantRunoff(options []uint, ballots []Ballot) RunoffResult {
+	active := make(map[uint]bool, len(options))
+	for _, opt := range options {
+		active[opt] = true
+	}
+
+	firstChoice := countRound(active, ballots)
+
+	total := 0
+	for _, ballot := range ballots {
+		total += ballot.weight()
+	}
+
+	var result RunoffResult
+	var history []map[uint]int
+
+	for round := 1; len(active) > 0; round++ {
+		counts := countRound(active, ballots)
+		history = append(history, counts)
+
+		continuing := 0
+		for _, votes := range counts {
+			continuing += votes
+		}
+
+		rr := RunoffRound{
+			Round:     round,
+			Tallies:   sortedTallies(counts),
+			Exhausted: total - continuing,
+			Majority:  continuing/2 + 1,
+		}
+
+		if continuing == 0 {
+			result.Rounds = append(result.Rounds, rr)
+			return result
+		}
+
+		leader := rr.Tallies[0]
+		if leader.Votes >= rr.Majority || len(active) == 1 {
+			winner := leader.OptionId
+			result.Winner = &winner
+			result.Rounds = append(result.Rounds, rr)
+			return result
+		}
+
+		eliminated, tied, rule := lowest(counts, history, firstChoice)
+		delete(active, eliminated)
+
+		rr.Eliminated = &eliminated
+		if len(tied) > 1 {
+			rr.Tied = tied
+			rr.TieBreak = rule
+		}
+		result.Rounds = append(result.Rounds, rr)
+	}
+
+	return result
+}
+
+func countRound(active map[uint]bool, ballots []Ballot) map[uint]int {
+	counts := make(map[uint]int, len(active))
+	for opt := range active {
+		counts[opt] = 0
+	}
+
+	for _, ballot := range ballots {
+		for _, opt := range ballot.Options {
+			if active[opt] {
+				counts[opt] += ballot.weight()
+				break
+			}
+		}
+	}
+
+	return counts
+}
+
+// elige la opcion a eliminar aplicando los desempates en orden
+func lowest(counts map[uint]int, history []map[uint]int, firstChoice map[uint]int) (uint, []uint, string) {
+	min := -1
+	for _, votes := range counts {
+		if min == -1 || votes < min {
+			min = votes
+		}
+	}
+
+	var tied []uint
+	for opt, votes := range counts {
+		if votes == min {
+			tied = append(tied, opt)
+		}
+	}
+	sort.Slice(tied, func(i, j int) bool { return tied[i] < tied[j] })
+
+	if len(tied) == 1 {
+		return tied[0], tied, ""
+	}
+
+	//rondas anteriores, de la mas reciente a la primera
+	candidates := tied
+	for i := len(history) - 2; i >= 0 && len(candidates) > 1; i-- {
+		candidates = minBy(candidates, history[i])
+	}
+	if len(candidates) == 1 {
+		return candidates[0], tied, TieBreakPreviousRound
+	}
+
+	candidates = minBy(candidates, firstChoice)
+	if len(candidates) == 1 {
+		return candidates[0], tied, TieBreakFirstChoice
+	}
+
+	return candidates[len(candidates)-1], tied, TieBreakOptionOrder
+}
+
+func minBy(options []uint, counts map[uint]int) []uint {
+	min := -1
+	for _, opt := range options {
+		if min == -1 || counts[opt] < min {
+			min = counts[opt]
+		}
+	}
+
+	var res []uint
+	for _, opt := range options {
+		if counts[opt] == min {
+			res = append(res, opt)
+		}
+	}
+	return res
+}
+
+// boletas sin peso cuentan como un voto
+func (b Ballot) weight() int {
+	if b.Weight <= 0 {
+		return 1
+	}
+	return b.Weight
+}
+
+func sortedTallies(counts map[uint]int) []OptionTally {
+	tallies := make([]OptionTally, 0, len(counts))
+	for opt, votes := range counts {
+		tallies = append(tallies, OptionTally{OptionId: opt, Votes: votes})
+	}
+
+	sort.Slice(tallies, func(i, j int) bool {
+		if tallies[i].Votes != tallies[j].Votes {
+			return tallies[i].Votes > tallies[j].Votes
+		}
+		return tallies[i].OptionId < tallies[j].OptionId
+	})
+	return tallies
+}
+
+// arma las boletas agrupando los votos de cada usuario (o de cada boleta secreta) por su posicion
+func (p ProposalResults) Ballots() []Ballot {
+	type rankedVote struct {
+		option uint
+		rank   int
+		weight int
+	}
+
+	var users []string
+	byUser := make(map[string][]rankedVote)
+	for _, option := range p.Options {
+		for _, vote := range option.Votes {
+			key := vote.BallotId
+			if key == "" {
+				key = fmt.Sprint(vote.UserId)
+			}
+
+			if _, ok := byUser[key]; !ok {
+				users = append(users, key)
+			}
+			byUser[key] = append(byUser[key], rankedVote{option: option.OptionId, rank: vote.Rank, weight: vote.Weight})
+		}
+	}
+
+	ballots := make([]Ballot, 0, len(users))
+	for _, user := range users {
+		votes := byUser[user]
+		sort.SliceStable(votes, func(i, j int) bool { return votes[i].rank < votes[j].rank })
+
+		ballot := Ballot{Options: make([]uint, 0, len(votes)), Weight: votes[0].weight}
+		for _, vote := range votes {
+			ballot.Options = append(ballot.Options, vote.option)
+		}
+		ballots = append(ballots, ballot)
+	}
+
+	return ballots
+}
@@ -0,0 +1,73 @@
+package valueobjects
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	BallotSingle   = "single"   //una opcion por votante
+	BallotRanked   = "ranked"   //lista ordenada de opciones, se resuelve por segunda vuelta instantanea (IRV)
+	BallotApproval = "approval" //se aprueban todas las opciones que se quiera
+	BallotMulti    = "multi"    //se eligen entre MinSelections y MaxSelections opciones
+)
+
+type (
+	BallotType struct {
+		BallotType    string
+		MinSelections int //solo approval y multi
+		MaxSelections int //0 = sin limite
+	}
+)
+
+func NewBallotType(ballotType string, minSelections, maxSelections int) (*BallotType, error) {
+	if ballotType == "" {
+		ballotType = BallotSingle
+	}
+
+	switch ballotType {
+	case BallotSingle, BallotRanked:
+		minSelections, maxSelections = 0, 0
+	case BallotApproval, BallotMulti:
+		if minSelections < 0 || maxSelections < 0 {
+			return nil, errors.New("selections bounds must be positive")
+		}
+		if minSelections == 0 {
+			minSelections = 1
+		}
+		if ballotType == BallotMulti && maxSelections == 0 {
+			return nil, errors.New("multi ballots require max_selections")
+		}
+		if maxSelections > 0 && minSelections > maxSelections {
+			return nil, errors.New("min_selections can't be greater than max_selections")
+		}
+	default:
+		return nil, errors.New("invalid ballot type")
+	}
+
+	return &BallotType{
+		BallotType:    ballotType,
+		MinSelections: minSelections,
+		MaxSelections: maxSelections,
+	}, nil
+}
+
+func (b BallotType) IsRanked() bool {
+	return b.BallotType == BallotRanked
+}
+
+// true si el votante puede elegir varias opciones sin orden
+func (b BallotType) IsMultiSelect() bool {
+	return b.BallotType == BallotApproval || b.BallotType == BallotMulti
+}
+
+// valida la cantidad de opciones elegidas contra los limites de la propuesta
+func (b BallotType) ValidateSelections(selected int) error {
+	if selected < b.MinSelections {
+		return fmt.Errorf("at least %d options must be selected", b.MinSelections)
+	}
+	if b.MaxSelections > 0 && selected > b.MaxSelections {
+		return fmt.Errorf("at most %d options can be selected", b.MaxSelections)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package valueobjects
+
+import "errors"
+
+const (
+	RuleSimpleMajority   = "simple_majority"   //mas de la mitad de los votos emitidos
+	RuleAbsoluteMajority = "absolute_majority" //mas de la mitad del peso total de la whitelist
+	RuleTwoThirds        = "two_thirds"        //al menos dos tercios de los votos emitidos
+	RuleUnanimity        = "unanimity"         //todos los votos emitidos por la misma opcion
+
+	AbstentionsExclude = "exclude" //las abstenciones no cuentan en la base
+	AbstentionsAgainst = "against" //las abstenciones cuentan en la base, como votos en contra
+)
+
+type (
+	DecisionRule struct {
+		Rule        string
+		Abstentions string
+	}
+)
+
+func NewDecisionRule(rule string, abstentions string) (*DecisionRule, error) {
+	if rule == "" {
+		rule = RuleSimpleMajority
+	}
+	if abstentions == "" {
+		abstentions = AbstentionsExclude
+	}
+
+	switch rule {
+	case RuleSimpleMajority, RuleAbsoluteMajority, RuleTwoThirds, RuleUnanimity:
+	default:
+		return nil, errors.New("invalid decision rule")
+	}
+
+	switch abstentions {
+	case AbstentionsExclude, AbstentionsAgainst:
+	default:
+		return nil, errors.New("invalid abstentions handling")
+	}
+
+	return &DecisionRule{
+		Rule:        rule,
+		Abstentions: abstentions,
+	}, nil
+}
+
+// votos necesarios para aprobar sobre la base dada
+func (d DecisionRule) Threshold(base int) int {
+	switch d.Rule {
+	case RuleTwoThirds:
+		return (2*base + 2) / 3
+	case RuleUnanimity:
+		return base
+	default:
+		return base/2 + 1
+	}
+}
@@ -13,6 +13,11 @@ func DomainToModel(proposal *domain.Proposal) *m.Proposal {
 		Archive:     &proposal.Archive().Archive,
 		Title:       proposal.Title().Title,
 		Description: &proposal.Description().Description,
+		BallotType:  proposal.BallotType().BallotType,
+		MinSelect:   proposal.BallotType().MinSelections,
+		MaxSelect:   proposal.BallotType().MaxSelections,
+		Rule:        proposal.DecisionRule().Rule,
+		Abstentions: proposal.DecisionRule().Abstentions,
 		RoomID:      proposal.RoomID().Id,
 	}
 }
@@ -38,12 +43,22 @@ func ModelToDomain(proposalModel *m.Proposal) (*domain.Proposal, error) {
 		return nil, err
 	}
 
+	ballotType, err := v.NewBallotType(proposalModel.BallotType, proposalModel.MinSelect, proposalModel.MaxSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	decisionRule, err := v.NewDecisionRule(proposalModel.Rule, proposalModel.Abstentions)
+	if err != nil {
+		return nil, err
+	}
+
 	roomID, err := sv.NewID(proposalModel.RoomID)
 	if err != nil {
 		return nil, err
 	}
 
 	return domain.NewProposal(
-		id, archive, *title, description, roomID,
+		id, archive, *title, description, *ballotType, *decisionRule, roomID,
 	), nil
 }
@@ -5,18 +5,44 @@ type Proposal struct {
 	Archive     *string `xorm:"'archive' null"` // Archivo con informacion detallada de la propuesta
 	Title       string  `xorm:"'title' not null"`
 	Description *string `xorm:"'description' null"`
+	BallotType  string  `xorm:"'ballot_type' varchar(16) not null default 'single'"`
+	MinSelect   int     `xorm:"'min_selections' not null default 0"`
+	MaxSelect   int     `xorm:"'max_selections' not null default 0"`
+	Rule        string  `xorm:"'decision_rule' varchar(24) not null default 'simple_majority'"`
+	Abstentions string  `xorm:"'abstentions' varchar(16) not null default 'exclude'"`
 	RoomID      uint    `xorm:"'room_id' index not null"`
 }
 
+type SqlChoiceResult struct {
+	ProposalId uint   `xorm:"proposal_id"`
+	Round      int    `xorm:"round"`
+	Choice     string `xorm:"choice"`
+	Count      int    `xorm:"count"`
+	Weight     int    `xorm:"weight"`
+}
+
 type SqlResult struct {
 	ProposalId          uint   `xorm:"proposal_id"`
 	ProposalTitle       string `xorm:"proposal_title"`
 	ProposalDescription string `xorm:"proposal_description"`
 	RoomID              uint   `xorm:"room_id"`
+	BallotType          string `xorm:"ballot_type"`
+	Rule                string `xorm:"decision_rule"`
+	Abstentions         string `xorm:"abstentions"`
+	Outcome             string `xorm:"outcome"`
+	OutcomeRule         string `xorm:"outcome_rule"`
+	WinnerID            uint   `xorm:"winner_option_id"`
+	Base                int    `xorm:"outcome_base"`
+	Threshold           int    `xorm:"outcome_threshold"`
 	OptionId            uint   `xorm:"option_id"`
 	OptionValue         string `xorm:"option_value"`
 	VoteId              uint   `xorm:"vote_id"`
 	UserId              uint   `xorm:"user_id"`
 	Username            string `xorm:"username"`
 	UserImage           string `xorm:"user_image"`
+	Rank                int    `xorm:"rank"`
+	BallotId            string `xorm:"ballot_id"`
+	Weight              int    `xorm:"weight"`
+	ProxyId             uint   `xorm:"proxy_id"`
+	Round               int    `xorm:"round"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// resultado de la votacion original (ronda 1) de la propuesta en cada sesion.
+// El de las re-votaciones se guarda en proposal_round
+type ProposalOutcome struct {
+	ID         uint      `xorm:"'id' pk autoincr"`
+	ProposalID uint      `xorm:"'proposal_id' not null unique(proposal_outcome_idx)"`
+	SessionID  uint      `xorm:"'session_id' not null unique(proposal_outcome_idx)"`
+	Outcome    string    `xorm:"'outcome' varchar(16) not null"`
+	Rule       string    `xorm:"'outcome_rule' varchar(24) not null"`
+	WinnerID   *uint     `xorm:"'winner_option_id' null"`
+	Base       int       `xorm:"'outcome_base' not null default 0"`
+	Threshold  int       `xorm:"'outcome_threshold' not null default 0"`
+	DecidedAt  time.Time `xorm:"'decided_at' not null"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// re-votaciones de la propuesta, la ronda 1 es la propia propuesta
+type ProposalRound struct {
+	ID         uint      `xorm:"'id' pk autoincr"`
+	ProposalID uint      `xorm:"'proposal_id' not null unique(proposal_round_idx)"`
+	SessionID  uint      `xorm:"'session_id' not null unique(proposal_round_idx)"`
+	Number     int       `xorm:"'number' not null unique(proposal_round_idx)"`
+	Options    []uint    `xorm:"'options' json null"` //null si se votan todas las opciones
+	Reason     string    `xorm:"'reason' not null default ''"`
+	StartedAt  time.Time `xorm:"'started_at' created"`
+
+	//resultado persistido al mostrar los resultados de la ronda
+	Outcome   *string    `xorm:"'outcome' varchar(16) null"`
+	Rule      *string    `xorm:"'outcome_rule' varchar(24) null"`
+	WinnerID  *uint      `xorm:"'winner_option_id' null"`
+	Base      int        `xorm:"'outcome_base' not null default 0"`
+	Threshold int        `xorm:"'outcome_threshold' not null default 0"`
+	DecidedAt *time.Time `xorm:"'decided_at' null"`
+}
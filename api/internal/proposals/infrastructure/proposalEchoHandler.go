@@ -10,6 +10,7 @@ import (
 
 	d "suffgo/internal/proposals/domain"
 	v "suffgo/internal/proposals/domain/valueObjects"
+	re "suffgo/internal/rooms/domain/errors"
 	rh "suffgo/internal/rooms/infrastructure"
 	se "suffgo/internal/shared/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
@@ -86,6 +87,16 @@ func (h *ProposalEchoHandler) CreateProposal(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	ballotType, err := v.NewBallotType(req.BallotType, req.MinSelect, req.MaxSelect)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	decisionRule, err := v.NewDecisionRule(req.Rule, req.Abstentions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	roomID, err := sv.NewID(req.RoomID)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -96,6 +107,8 @@ func (h *ProposalEchoHandler) CreateProposal(c echo.Context) error {
 		archive,
 		*title,
 		description,
+		*ballotType,
+		*decisionRule,
 		roomID,
 	)
 
@@ -115,6 +128,11 @@ func (h *ProposalEchoHandler) CreateProposal(c echo.Context) error {
 		Archive:     createdProp.Archive().URL(),
 		Title:       createdProp.Title().Title,
 		Description: &createdProp.Description().Description,
+		BallotType:  createdProp.BallotType().BallotType,
+		MinSelect:   createdProp.BallotType().MinSelections,
+		MaxSelect:   createdProp.BallotType().MaxSelections,
+		Rule:        createdProp.DecisionRule().Rule,
+		Abstentions: createdProp.DecisionRule().Abstentions,
 		RoomID:      createdProp.RoomID().Id,
 	}
 
@@ -143,6 +161,11 @@ func (h *ProposalEchoHandler) GetAllProposal(c echo.Context) error {
 			Archive:     prop.Archive().URL(),
 			Title:       prop.Title().Title,
 			Description: &prop.Description().Description,
+			BallotType:  prop.BallotType().BallotType,
+			MinSelect:   prop.BallotType().MinSelections,
+			MaxSelect:   prop.BallotType().MaxSelections,
+			Rule:        prop.DecisionRule().Rule,
+			Abstentions: prop.DecisionRule().Abstentions,
 		}
 		proposalDTO = append(proposalDTO, *propDTO)
 	}
@@ -174,6 +197,11 @@ func (h *ProposalEchoHandler) GetProposalByID(c echo.Context) error {
 		Archive:     proposal.Archive().URL(),
 		Title:       proposal.Title().Title,
 		Description: &proposal.Description().Description,
+		BallotType:  proposal.BallotType().BallotType,
+		MinSelect:   proposal.BallotType().MinSelections,
+		MaxSelect:   proposal.BallotType().MaxSelections,
+		Rule:        proposal.DecisionRule().Rule,
+		Abstentions: proposal.DecisionRule().Abstentions,
 	}
 	return c.JSON(http.StatusOK, proposalDTO)
 }
@@ -246,6 +274,33 @@ func (h *ProposalEchoHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	//si no se envia se mantiene el tipo de boleta actual
+	BallotType := currentProposal.BallotType()
+	if req.BallotType != "" {
+		newBallotType, err := v.NewBallotType(req.BallotType, req.MinSelect, req.MaxSelect)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		BallotType = *newBallotType
+	}
+
+	//idem con la regla de decision, cada campo vacio conserva su valor
+	DecisionRule := currentProposal.DecisionRule()
+	if req.Rule != "" || req.Abstentions != "" {
+		rule, abstentions := DecisionRule.Rule, DecisionRule.Abstentions
+		if req.Rule != "" {
+			rule = req.Rule
+		}
+		if req.Abstentions != "" {
+			abstentions = req.Abstentions
+		}
+		newDecisionRule, err := v.NewDecisionRule(rule, abstentions)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		DecisionRule = *newDecisionRule
+	}
+
 	RoomID := currentProposal.RoomID()
 
 	proposal := d.NewProposal(
@@ -253,6 +308,8 @@ func (h *ProposalEchoHandler) Update(c echo.Context) error {
 		Archive,
 		*Title,
 		Description,
+		BallotType,
+		DecisionRule,
 		&RoomID,
 	)
 
@@ -278,6 +335,11 @@ func (h *ProposalEchoHandler) Update(c echo.Context) error {
 		Archive:     updatedProposal.Archive().URL(),
 		Title:       updatedProposal.Title().Title,
 		Description: &updatedProposal.Description().Description,
+		BallotType:  updatedProposal.BallotType().BallotType,
+		MinSelect:   updatedProposal.BallotType().MinSelections,
+		MaxSelect:   updatedProposal.BallotType().MaxSelections,
+		Rule:        updatedProposal.DecisionRule().Rule,
+		Abstentions: updatedProposal.DecisionRule().Abstentions,
 		RoomID:      updatedProposal.RoomID().Id,
 	}
 
@@ -317,6 +379,11 @@ func (h *ProposalEchoHandler) GetProposalsByRoomId(c echo.Context) error {
 			Archive:     prop.Archive().URL(),
 			Title:       prop.Title().Title,
 			Description: &prop.Description().Description,
+			BallotType:  prop.BallotType().BallotType,
+			MinSelect:   prop.BallotType().MinSelections,
+			MaxSelect:   prop.BallotType().MaxSelections,
+			Rule:        prop.DecisionRule().Rule,
+			Abstentions: prop.DecisionRule().Abstentions,
 			RoomID:      roomId.Id,
 		}
 		proposalDTO = append(proposalDTO, *propDTO)
@@ -338,8 +405,20 @@ func (h *ProposalEchoHandler) GetResultsByRoom(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	proposals, err := h.GetResultsByRoomUsecase.Execute(roomId)
+	//sesion de votacion opcional, por defecto la oficial
+	var sessionId *sv.ID
+	if sessionParam := c.QueryParam("session"); sessionParam != "" {
+		sessionId, err = sv.NewID(sessionParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	proposals, err := h.GetResultsByRoomUsecase.Execute(roomId, sessionId)
 	if err != nil {
+		if errors.Is(err, re.ErrSessionNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
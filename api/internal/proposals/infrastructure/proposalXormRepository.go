@@ -5,10 +5,13 @@ import (
 	"suffgo/cmd/database"
 	d "suffgo/internal/proposals/domain"
 	pe "suffgo/internal/proposals/domain/errors"
+	v "suffgo/internal/proposals/domain/valueObjects"
 	"suffgo/internal/proposals/infrastructure/mappers"
 	m "suffgo/internal/proposals/infrastructure/models"
 	se "suffgo/internal/shared/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
+	vd "suffgo/internal/votes/domain"
+	"time"
 )
 
 type ProposalXormRepository struct {
@@ -27,6 +30,11 @@ func (s *ProposalXormRepository) Save(proposal d.Proposal) (*d.Proposal, error)
 		Archive:     &proposal.Archive().Archive,
 		Title:       proposal.Title().Title,
 		Description: &proposal.Description().Description,
+		BallotType:  proposal.BallotType().BallotType,
+		MinSelect:   proposal.BallotType().MinSelections,
+		MaxSelect:   proposal.BallotType().MaxSelections,
+		Rule:        proposal.DecisionRule().Rule,
+		Abstentions: proposal.DecisionRule().Abstentions,
 		RoomID:      proposal.RoomID().Id,
 	}
 
@@ -153,7 +161,8 @@ func (s *ProposalXormRepository) GetByRoom(roomId sv.ID) ([]d.Proposal, error) {
 	return proposalsDomain, nil
 }
 
-func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID) ([]d.ProposalResults, error) {
+// resultados de la sala contando solo los votos de la sesion indicada
+func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID, sessionId sv.ID) ([]d.ProposalResults, error) {
 	var rawResults []m.SqlResult
 	err := s.db.GetDb().SQL(`
     SELECT 
@@ -161,19 +170,33 @@ func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID) ([]d.ProposalRes
         p.title AS proposal_title,
         p.description AS proposal_description,
         p.room_id AS room_id,
+        p.ballot_type AS ballot_type,
+        p.decision_rule AS decision_rule,
+        p.abstentions AS abstentions,
+        COALESCE(po.outcome, '') AS outcome,
+        COALESCE(po.outcome_rule, '') AS outcome_rule,
+        COALESCE(po.winner_option_id, 0) AS winner_option_id,
+        COALESCE(po.outcome_base, 0) AS outcome_base,
+        COALESCE(po.outcome_threshold, 0) AS outcome_threshold,
         o.id AS option_id,
         o.value AS option_value,
         v.id AS vote_id,
         u.id AS user_id,
         u.username AS username,
-        u.image AS user_image
+        u.image AS user_image,
+        v.rank AS rank,
+        v.ballot_id AS ballot_id,
+        v.weight AS weight,
+        v.proxy_id AS proxy_id,
+        COALESCE(v.round, 1) AS round
     FROM proposal p
+    LEFT JOIN proposal_outcome po ON po.proposal_id = p.id AND po.session_id = ?
     LEFT JOIN "option" o ON o.proposal_id = p.id 
-    LEFT JOIN vote v ON v.option_id = o.id
+    LEFT JOIN vote v ON v.option_id = o.id AND v.session_id = ?
     LEFT JOIN users u ON u.id = v.user_id
     WHERE p.room_id = ?
-    ORDER BY p.id, o.id, v.id
-`, roomId.Id).Find(&rawResults)
+    ORDER BY p.id, o.id, v.round, v.user_id, v.ballot_id, v.rank, v.id
+`, sessionId.Id, sessionId.Id, roomId.Id).Find(&rawResults)
 
 	if err != nil {
 		return nil, err
@@ -198,8 +221,31 @@ func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID) ([]d.ProposalRes
 				ProposalTitle:       row.ProposalTitle,
 				ProposalDescription: row.ProposalDescription,
 				RoomID:              row.RoomID,
+				SessionID:           sessionId.Id,
+				BallotType:          row.BallotType,
 				Options:             []d.OptionResults{},
 			}
+
+			decisionRule, err := v.NewDecisionRule(row.Rule, row.Abstentions)
+			if err != nil {
+				return nil, err
+			}
+			currentProposal.DecisionRule = *decisionRule
+
+			//resultado guardado de la ronda 1 en la sesion
+			if row.Outcome != "" {
+				outcome := &d.Outcome{
+					Outcome:   row.Outcome,
+					Rule:      row.OutcomeRule,
+					Base:      row.Base,
+					Threshold: row.Threshold,
+				}
+				if row.WinnerID != 0 {
+					winner := row.WinnerID
+					outcome.Winner = &winner
+				}
+				currentProposal.Outcome = outcome
+			}
 			currentOption = nil
 		}
 
@@ -220,6 +266,11 @@ func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID) ([]d.ProposalRes
 				UserId:    row.UserId,
 				Username:  row.Username,
 				UserImage: row.UserImage,
+				Rank:      row.Rank,
+				BallotId:  row.BallotId,
+				Weight:    row.Weight,
+				ProxyId:   row.ProxyId,
+				Round:     row.Round,
 			})
 		}
 	}
@@ -231,5 +282,192 @@ func (s *ProposalXormRepository) GetResultsByRoom(roomId sv.ID) ([]d.ProposalRes
 		results = append(results, *currentProposal)
 	}
 
+	//abstenciones y votos en blanco no tienen opcion, se cuentan aparte
+	var choices []m.SqlChoiceResult
+	err = s.db.GetDb().SQL(`
+    SELECT
+        v.proposal_id AS proposal_id,
+        v.round AS round,
+        v.choice AS choice,
+        COUNT(*) AS count,
+        COALESCE(SUM(v.weight), 0) AS weight
+    FROM vote v
+    JOIN proposal p ON p.id = v.proposal_id
+    WHERE p.room_id = ? AND v.session_id = ? AND v.option_id IS NULL
+    GROUP BY v.proposal_id, v.round, v.choice
+`, roomId.Id, sessionId.Id).Find(&choices)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rounds, err := s.roomRounds(roomId, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	//cada re-votacion se informa por separado, la propuesta muestra la ultima ronda
+	for i := range results {
+		extra := rounds[results[i].ProposalId]
+		if len(extra) == 0 {
+			results[i].Round = 1
+			setChoices(&results[i], choices)
+			continue
+		}
+
+		all := []d.ProposalResults{results[i].ForRound(1, nil)}
+		for _, round := range extra {
+			roundResults := results[i].ForRound(round.Number, round.Options)
+			roundResults.Outcome = round.Outcome
+			all = append(all, roundResults)
+		}
+		for j := range all {
+			setChoices(&all[j], choices)
+		}
+
+		results[i] = all[len(all)-1]
+		results[i].Rounds = all
+	}
+
 	return results, nil
 }
+
+// abstenciones y votos en blanco de la ronda de la propuesta
+func setChoices(results *d.ProposalResults, choices []m.SqlChoiceResult) {
+	for _, choice := range choices {
+		if choice.ProposalId != results.ProposalId || choice.Round != results.Round {
+			continue
+		}
+		counted := d.ChoiceResults{Count: choice.Count, Weight: choice.Weight}
+		if choice.Choice == vd.ChoiceAbstain {
+			results.Abstain = counted
+		} else {
+			results.Blank = counted
+		}
+	}
+}
+
+// re-votaciones de las propuestas de la sala en la sesion, por propuesta y en orden
+func (s *ProposalXormRepository) roomRounds(roomId sv.ID, sessionId sv.ID) (map[uint][]d.Round, error) {
+	var models []m.ProposalRound
+	err := s.db.GetDb().
+		Where("session_id = ? AND proposal_id IN (SELECT id FROM proposal WHERE room_id = ?)", sessionId.Id, roomId.Id).
+		Asc("proposal_id", "number").
+		Find(&models)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make(map[uint][]d.Round)
+	for i := range models {
+		rounds[models[i].ProposalID] = append(rounds[models[i].ProposalID], *roundToDomain(&models[i]))
+	}
+	return rounds, nil
+}
+
+// guarda el resultado de la propuesta para que el historial no dependa de recalcularlo.
+// El de las re-votaciones se guarda en su ronda
+func (s *ProposalXormRepository) SaveOutcome(proposalID sv.ID, sessionID sv.ID, round int, outcome d.Outcome) error {
+	now := time.Now()
+	if round > 1 {
+		affected, err := s.db.GetDb().
+			Where("proposal_id = ? AND session_id = ? AND number = ?", proposalID.Id, sessionID.Id, round).
+			Cols("outcome", "outcome_rule", "winner_option_id", "outcome_base", "outcome_threshold", "decided_at").
+			Update(&m.ProposalRound{
+				Outcome:   &outcome.Outcome,
+				Rule:      &outcome.Rule,
+				WinnerID:  outcome.Winner,
+				Base:      outcome.Base,
+				Threshold: outcome.Threshold,
+				DecidedAt: &now,
+			})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return pe.ErrRoundNotFound
+		}
+		return nil
+	}
+
+	exists, err := s.db.GetDb().ID(proposalID.Id).Exist(&m.Proposal{})
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return pe.ErrPropNotFound
+	}
+
+	//una fila por sesion: guardar el resultado de una sesion nueva no pisa el de las archivadas
+	_, err = s.db.GetDb().Exec(`
+    INSERT INTO proposal_outcome (proposal_id, session_id, outcome, outcome_rule, winner_option_id, outcome_base, outcome_threshold, decided_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    ON CONFLICT (proposal_id, session_id) DO UPDATE SET
+        outcome = EXCLUDED.outcome,
+        outcome_rule = EXCLUDED.outcome_rule,
+        winner_option_id = EXCLUDED.winner_option_id,
+        outcome_base = EXCLUDED.outcome_base,
+        outcome_threshold = EXCLUDED.outcome_threshold,
+        decided_at = EXCLUDED.decided_at
+`, proposalID.Id, sessionID.Id, outcome.Outcome, outcome.Rule, outcome.Winner, outcome.Base, outcome.Threshold, now)
+	return err
+}
+
+func (s *ProposalXormRepository) SaveRound(round d.Round) (*d.Round, error) {
+	model := &m.ProposalRound{
+		ProposalID: round.ProposalID,
+		SessionID:  round.SessionID,
+		Number:     round.Number,
+		Options:    round.Options,
+		Reason:     round.Reason,
+	}
+
+	//el indice unico evita que dos instancias abran la misma ronda
+	if _, err := s.db.GetDb().Insert(model); err != nil {
+		return nil, err
+	}
+
+	return roundToDomain(model), nil
+}
+
+func (s *ProposalXormRepository) GetRounds(proposalID sv.ID, sessionID sv.ID) ([]d.Round, error) {
+	var models []m.ProposalRound
+	err := s.db.GetDb().
+		Where("proposal_id = ? AND session_id = ?", proposalID.Id, sessionID.Id).
+		Asc("number").
+		Find(&models)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make([]d.Round, 0, len(models))
+	for i := range models {
+		rounds = append(rounds, *roundToDomain(&models[i]))
+	}
+	return rounds, nil
+}
+
+func roundToDomain(model *m.ProposalRound) *d.Round {
+	round := &d.Round{
+		ID:         model.ID,
+		ProposalID: model.ProposalID,
+		SessionID:  model.SessionID,
+		Number:     model.Number,
+		Options:    model.Options,
+		Reason:     model.Reason,
+		StartedAt:  model.StartedAt,
+	}
+
+	if model.Outcome != nil {
+		round.Outcome = &d.Outcome{
+			Outcome:   *model.Outcome,
+			Winner:    model.WinnerID,
+			Base:      model.Base,
+			Threshold: model.Threshold,
+		}
+		if model.Rule != nil {
+			round.Outcome.Rule = *model.Rule
+		}
+	}
+	return round
+}
@@ -22,7 +22,11 @@ func NewAddSingleUserUsecase(repository domain.RoomRepository, userRepository us
 	}
 }
 
-func (s *AddSingleUserUsecase) Execute(userData string, roomID, adminID sv.ID) error {
+func (s *AddSingleUserUsecase) Execute(userData string, roomID, adminID sv.ID, weight int) error {
+
+	if weight <= 0 {
+		return roomErrors.ErrInvalidWeight
+	}
 
 	//chequear que el administrador de la sala sea el que esta intentando agregar usuarios
 	room, err := s.repository.GetByID(roomID)
@@ -59,7 +63,7 @@ func (s *AddSingleUserUsecase) Execute(userData string, roomID, adminID sv.ID) e
 
 	if user != nil {
 
-		err = s.repository.AddToWhitelist(roomID, user.ID())
+		err = s.repository.AddToWhitelist(roomID, user.ID(), weight)
 
 		if err != nil {
 			return nil
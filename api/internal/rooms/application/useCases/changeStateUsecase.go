@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type ChangeStateUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewChangeStateUsecase(roomRepo domain.RoomRepository) *ChangeStateUsecase {
+	return &ChangeStateUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// el dueño cancela una sala que no empezo o archiva una terminada. El resto de los
+// estados los cambia la sala en vivo o el scheduler
+func (s *ChangeStateUsecase) Execute(roomId, userId sv.ID, state, reason string) error {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return roomerr.ErrRoomNotFound
+	}
+
+	if room.AdminID().Id != userId.Id {
+		return roomerr.ErrUserNotAdmin
+	}
+
+	switch state {
+	case v.StateCancelled:
+		//una sala en vivo se cierra desde la sala
+		if !room.State().NotStarted() {
+			return roomerr.ErrStateConstraint
+		}
+	case v.StateArchived:
+	default:
+		return roomerr.ErrInvalidTransition
+	}
+
+	transition, err := room.ChangeState(state, &userId, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.roomRep.ChangeState(*transition)
+}
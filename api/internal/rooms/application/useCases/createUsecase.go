@@ -25,8 +25,6 @@ func NewCreateUsecase(roomRepo domain.RoomRepository, srRepo domsettingroom.Sett
 }
 
 func (s *CreateUsecase) Execute(roomData domain.Room) (*domain.Room, error) {
-	roomData.State().SetState("created")
-
 	inviteCode, err := v.NewInviteCode(uuid.New().String()[:6])
 	if err != nil {
 		return nil, err
@@ -40,7 +38,7 @@ func (s *CreateUsecase) Execute(roomData domain.Room) (*domain.Room, error) {
 	}
 
 	if createdRoom.IsFormal().IsFormal {
-		err = s.roomRepository.AddToWhitelist(createdRoom.ID(), createdRoom.AdminID())
+		err = s.roomRepository.AddToWhitelist(createdRoom.ID(), createdRoom.AdminID(), 1)
 		if err != nil {
 			return nil, err
 		}
@@ -62,9 +60,13 @@ func generateDefaultRoomConfig(roomId sv.ID) domsettingroom.SettingRoom {
 
 	privacy, _ := srv.NewPrivacy(&t)
 	proposalTimer, _ := srv.NewProposalTimer(30) //30 segundos por defecto
-	quorum, _ := srv.NewQuorum(&zero)
+	quorum, _ := srv.NewQuorum(&zero, srv.QuorumAbsolute)
 	timeAndDate, _ := srv.NewDateTime(nil)
 	voterLimit, _ := srv.NewVoterLimit(100)
+	waitingList, _ := srv.NewWaitingList(&t)
+	secretBallot, _ := srv.NewSecretBallot(&t)
+	abstainQuorum, _ := srv.NewAbstainQuorum(nil)
+	voteChange, _ := srv.NewVoteChange(&t)
 
 	return *domsettingroom.NewSettingRoom(
 		nil,
@@ -73,6 +75,10 @@ func generateDefaultRoomConfig(roomId sv.ID) domsettingroom.SettingRoom {
 		*quorum,
 		*timeAndDate,
 		voterLimit,
+		*waitingList,
+		*secretBallot,
+		*abstainQuorum,
+		*voteChange,
 		&roomId,
 	)
 }
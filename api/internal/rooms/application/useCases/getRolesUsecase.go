@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type GetRolesUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewGetRolesUsecase(roomRepo domain.RoomRepository) *GetRolesUsecase {
+	return &GetRolesUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// dueño y roles asignados de la sala, los usuarios que no aparecen son voter
+func (s *GetRolesUsecase) Execute(roomId sv.ID) ([]domain.RoomRoleDTO, error) {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return nil, roomerr.ErrRoomNotFound
+	}
+
+	roles, err := s.roomRep.GetRoles(roomId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []domain.RoomRoleDTO{{UserID: room.AdminID().Id, Role: v.RoleOwner}}
+	for userID, role := range roles {
+		if userID == room.AdminID().Id {
+			continue
+		}
+		result = append(result, domain.RoomRoleDTO{UserID: userID, Role: role})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type GetSessionsUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewGetSessionsUsecase(roomRepo domain.RoomRepository) *GetSessionsUsecase {
+	return &GetSessionsUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// sesiones de votacion de la sala ordenadas por numero
+func (s *GetSessionsUsecase) Execute(roomId sv.ID) ([]domain.VotingSession, error) {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return nil, roomerr.ErrRoomNotFound
+	}
+
+	return s.roomRep.GetSessions(roomId)
+}
@@ -0,0 +1,27 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type GetTimelineUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewGetTimelineUsecase(roomRepo domain.RoomRepository) *GetTimelineUsecase {
+	return &GetTimelineUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// cambios de estado de la sala, del mas viejo al mas nuevo
+func (s *GetTimelineUsecase) Execute(roomId sv.ID) ([]domain.StateTransition, error) {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return nil, roomerr.ErrRoomNotFound
+	}
+
+	return s.roomRep.GetTimeline(roomId)
+}
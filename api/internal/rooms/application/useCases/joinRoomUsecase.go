@@ -4,6 +4,7 @@ import (
 	"errors"
 	"suffgo/internal/rooms/domain"
 	rerr "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
 	srdom "suffgo/internal/settingsRoom/domain"
 	sv "suffgo/internal/shared/domain/valueObjects"
 )
@@ -11,12 +12,14 @@ import (
 type JoinRoomUsecase struct {
 	roomRepo        domain.RoomRepository
 	setrRepo srdom.SettingRoomRepository
+	occupancy domain.LobbyOccupancy
 }
 
-func NewJoinRoomUsecase(repository domain.RoomRepository, srRepo srdom.SettingRoomRepository) *JoinRoomUsecase {
+func NewJoinRoomUsecase(repository domain.RoomRepository, srRepo srdom.SettingRoomRepository, occupancy domain.LobbyOccupancy) *JoinRoomUsecase {
 	return &JoinRoomUsecase{
 		roomRepo: repository,
 		setrRepo: srRepo,
+		occupancy: occupancy,
 	}
 }
 
@@ -38,7 +41,14 @@ func (s *JoinRoomUsecase) Execute(roomCode string, userID sv.ID) (*domain.Room,
 	}
 
 
-	if *setroom.Privacy().Privacy {
+	//observadores y co_admin pueden entrar aunque no esten en la whitelist
+	roles, err := s.roomRepo.GetRoles(room.ID())
+	if err != nil {
+		return nil, err
+	}
+	role := roles[userID.Id]
+
+	if *setroom.Privacy().Privacy && role == "" {
 		//check whitelist en user_room. Aca estoy asumiendo que todas las salas formales usan whitelist
 		can, err := s.roomRepo.UserInWhitelist(room.ID(), userID)
 
@@ -51,5 +61,13 @@ func (s *JoinRoomUsecase) Execute(roomCode string, userID sv.ID) (*domain.Room,
 		}
 	}
 
+	//limite de votantes: si la sala esta llena y no hay lista de espera se rechaza
+	limit := setroom.VoterLimit().VoterLimit
+	if limit > 0 && s.occupancy != nil && !setroom.WaitingList().Enabled() && userID.Id != room.AdminID().Id && role != v.RoleObserver {
+		if !s.occupancy.IsConnected(room.ID(), userID) && s.occupancy.ConnectedVoters(room.ID()) >= limit {
+			return nil, rerr.ErrVoterLimit
+		}
+	}
+
 	return room, nil
 }
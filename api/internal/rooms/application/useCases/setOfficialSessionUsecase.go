@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type SetOfficialSessionUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewSetOfficialSessionUsecase(roomRepo domain.RoomRepository) *SetOfficialSessionUsecase {
+	return &SetOfficialSessionUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// el dueño elige la sesion que se usa para los resultados de la sala
+func (s *SetOfficialSessionUsecase) Execute(roomId, userId, sessionId sv.ID) error {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return roomerr.ErrRoomNotFound
+	}
+
+	if room.AdminID().Id != userId.Id {
+		return roomerr.ErrUserNotAdmin
+	}
+
+	return s.roomRep.SetOfficialSession(roomId, sessionId)
+}
@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	udom "suffgo/internal/users/domain"
+	usererr "suffgo/internal/users/domain/errors"
+)
+
+type TransferOwnershipUsecase struct {
+	roomRep domain.RoomRepository
+	userRep udom.UserRepository
+	lobby   domain.LobbyRoles
+}
+
+func NewTransferOwnershipUsecase(roomRepo domain.RoomRepository, userRepo udom.UserRepository, lobby domain.LobbyRoles) *TransferOwnershipUsecase {
+	return &TransferOwnershipUsecase{
+		roomRep: roomRepo,
+		userRep: userRepo,
+		lobby:   lobby,
+	}
+}
+
+// el dueño actual cede la sala, queda como co_admin
+func (s *TransferOwnershipUsecase) Execute(roomId, newOwnerId, ownerId sv.ID) error {
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return roomerr.ErrRoomNotFound
+	}
+
+	if room.AdminID().Id != ownerId.Id {
+		return roomerr.ErrUserNotAdmin
+	}
+
+	if newOwnerId.Id == ownerId.Id {
+		return nil
+	}
+
+	user, err := s.userRep.GetByID(newOwnerId)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return usererr.ErrUserNotFound
+	}
+
+	if err := s.roomRep.TransferOwnership(roomId, newOwnerId); err != nil {
+		return err
+	}
+
+	s.lobby.RolesChanged(roomId)
+	return nil
+}
@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	udom "suffgo/internal/users/domain"
+	usererr "suffgo/internal/users/domain/errors"
+)
+
+type UpdateRoleUsecase struct {
+	roomRep domain.RoomRepository
+	userRep udom.UserRepository
+	lobby   domain.LobbyRoles
+}
+
+func NewUpdateRoleUsecase(roomRepo domain.RoomRepository, userRepo udom.UserRepository, lobby domain.LobbyRoles) *UpdateRoleUsecase {
+	return &UpdateRoleUsecase{
+		roomRep: roomRepo,
+		userRep: userRepo,
+		lobby:   lobby,
+	}
+}
+
+// asigna co_admin, voter u observer. El dueño solo cambia con una transferencia
+func (s *UpdateRoleUsecase) Execute(roomId, userId, ownerId sv.ID, role string) error {
+	newRole, err := v.NewRole(role)
+	if err != nil || newRole.Role == v.RoleOwner {
+		return roomerr.ErrInvalidRole
+	}
+
+	//validar sala
+	room, err := s.roomRep.GetByID(roomId)
+	if err != nil || room == nil {
+		return roomerr.ErrRoomNotFound
+	}
+
+	//solo el dueño asigna roles
+	if room.AdminID().Id != ownerId.Id {
+		return roomerr.ErrUserNotAdmin
+	}
+
+	if room.AdminID().Id == userId.Id {
+		return roomerr.ErrInvalidRole
+	}
+
+	user, err := s.userRep.GetByID(userId)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return usererr.ErrUserNotFound
+	}
+
+	if err := s.roomRep.SetRole(roomId, userId, newRole.Role); err != nil {
+		return err
+	}
+
+	s.lobby.RolesChanged(roomId)
+	return nil
+}
@@ -29,7 +29,7 @@ func (u *UpdateRoomUsecase) Execute(room *domain.Room, userID sv.ID) (*domain.Ro
 	}
 
 	//solo la puede editar si la sala no inicio
-	if existingRoom.State().CurrentState !=  "created" {
+	if !existingRoom.State().NotStarted() {
 		return nil, e.ErrStateConstraint
 	} 
 
@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type UpdateWeightUsecase struct {
+	roomRep domain.RoomRepository
+}
+
+func NewUpdateWeightUsecase(roomRepo domain.RoomRepository) *UpdateWeightUsecase {
+	return &UpdateWeightUsecase{
+		roomRep: roomRepo,
+	}
+}
+
+// actualiza el peso del voto de un usuario de la whitelist, los votos ya emitidos conservan el peso anterior
+func (s *UpdateWeightUsecase) Execute(roomId, userId, adminId sv.ID, weight int) error {
+
+	if weight <= 0 {
+		return roomerr.ErrInvalidWeight
+	}
+
+	//validar sala
+	room, err := s.roomRep.GetByID(roomId)
+
+	if err != nil || room == nil {
+		return roomerr.ErrRoomNotFound
+	}
+
+	//validar admin
+	if room.AdminID().Id != adminId.Id {
+		return roomerr.ErrUserNotAdmin
+	}
+
+	return s.roomRep.UpdateWhitelistWeight(roomId, userId, weight)
+}
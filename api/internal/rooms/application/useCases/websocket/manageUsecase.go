@@ -1,16 +1,21 @@
 package websocket
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	dldom "suffgo/internal/delegations/domain"
 	optdom "suffgo/internal/options/domain"
 	propdom "suffgo/internal/proposals/domain"
 	"suffgo/internal/rooms/domain"
 	roomerr "suffgo/internal/rooms/domain/errors"
+	roomvo "suffgo/internal/rooms/domain/valueObjects"
+	srdom "suffgo/internal/settingsRoom/domain"
 	userdom "suffgo/internal/users/domain"
 	votedom "suffgo/internal/votes/domain"
 
@@ -19,12 +24,18 @@ import (
 )
 
 type ManageWsUsecase struct {
-	rooms        map[sv.ID]*socketStructs.RoomLobby
-	userRepo     userdom.UserRepository
-	roomRepo     domain.RoomRepository
-	proposalRepo propdom.ProposalRepository
-	optionsRepo  optdom.OptionRepository
-	voteRepo     votedom.VoteRepository
+	roomsmx        sync.RWMutex
+	rooms          map[sv.ID]*socketStructs.RoomLobby
+	opening        map[sv.ID]chan struct{}
+	userRepo       userdom.UserRepository
+	roomRepo       domain.RoomRepository
+	proposalRepo   propdom.ProposalRepository
+	optionsRepo    optdom.OptionRepository
+	voteRepo       votedom.VoteRepository
+	settingRepo    srdom.SettingRoomRepository
+	delegationRepo dldom.DelegationRepository
+	bus            domain.LobbyBus
+	heartbeat      socketStructs.Heartbeat
 }
 
 func NewManageWsUsecase(
@@ -33,19 +44,40 @@ func NewManageWsUsecase(
 	proposalRepo propdom.ProposalRepository,
 	optionsRepo optdom.OptionRepository,
 	votesRepo votedom.VoteRepository,
+	settingRepo srdom.SettingRoomRepository,
+	delegationRepo dldom.DelegationRepository,
+	bus domain.LobbyBus,
+	heartbeat socketStructs.Heartbeat,
 ) *ManageWsUsecase {
 
 	return &ManageWsUsecase{
-		roomRepo:     repo,
-		userRepo:     userRepo,
-		proposalRepo: proposalRepo,
-		optionsRepo:  optionsRepo,
-		voteRepo:     votesRepo,
-		rooms:        make(map[sv.ID]*socketStructs.RoomLobby),
+		roomRepo:       repo,
+		userRepo:       userRepo,
+		proposalRepo:   proposalRepo,
+		optionsRepo:    optionsRepo,
+		voteRepo:       votesRepo,
+		settingRepo:    settingRepo,
+		delegationRepo: delegationRepo,
+		bus:            bus,
+		heartbeat:      heartbeat,
+		rooms:          make(map[sv.ID]*socketStructs.RoomLobby),
+		opening:        make(map[sv.ID]chan struct{}),
 	}
 }
 
-func (s *ManageWsUsecase) Execute(ws *websocket.Conn, userId, roomId sv.ID) error {
+// resumeToken es el token recibido en el evento session, vacio en la primera conexion.
+// version es la version del protocolo que pide el cliente, 0 si no la indica
+func (s *ManageWsUsecase) Execute(ws *websocket.Conn, userId, roomId sv.ID, resumeToken string, version int) error {
+
+	version, ok := socketStructs.NegotiateVersion(version)
+	if !ok {
+		ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(4005, "Version del protocolo no soportada"),
+			time.Now().Add(time.Second),
+		)
+		return nil
+	}
 
 	user, err := s.userRepo.GetByID(userId)
 	if err != nil {
@@ -53,72 +85,350 @@ func (s *ManageWsUsecase) Execute(ws *websocket.Conn, userId, roomId sv.ID) erro
 	}
 	var client *socketStructs.Client
 	reconnect := false
-	if s.rooms[roomId] != nil {
-		for cKey := range s.rooms[roomId].Clients() {
-			if cKey.User.ID().Id == user.ID().Id {
-				// Ya está conectado => rechazamos la nueva conexión
+
+	s.roomsmx.RLock()
+	lobby := s.rooms[roomId]
+	s.roomsmx.RUnlock()
+
+	if lobby != nil {
+		for cKey := range lobby.Clients() {
+			if cKey.User.ID().Id != user.ID().Id {
+				continue
+			}
+
+			//con el token retoma su lugar, aunque el servidor no haya detectado el corte de la conexion anterior
+			if resumeToken != "" && resumeToken == cKey.ResumeToken() && lobby.Resume(cKey, ws, version) {
+				client = cKey
+				reconnect = true
+				break
+			}
+
+			//esta esperando reconexion: solo se acepta con su token
+			if cKey.Conn() == nil {
 				ws.WriteControl(
 					websocket.CloseMessage,
-					websocket.FormatCloseMessage(4002, "Ya estas conectado a la sala"),
+					websocket.FormatCloseMessage(4004, "Token de reconexion invalido"),
 					time.Now().Add(time.Second),
 				)
 				return nil
 			}
+
+			// Ya está conectado => rechazamos la nueva conexión
+			ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(4002, "Ya estas conectado a la sala"),
+				time.Now().Add(time.Second),
+			)
+			return nil
+		}
+
+		//tambien puede estar conectado a la sala en otra instancia
+		if client == nil && lobby.ConnectedRemotely(user.ID().Id) {
+			ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(4002, "Ya estas conectado a la sala"),
+				time.Now().Add(time.Second),
+			)
+			return nil
 		}
 	}
 
 	if !reconnect {
-		client = socketStructs.NewClient(ws, *user)
+		client = socketStructs.NewClient(ws, *user, s.heartbeat)
+		client.SetVersion(version)
 	}
 
-	if s.rooms[roomId] == nil {
-		room, err := s.roomRepo.GetByID(roomId)
+	if lobby == nil {
+		lobby, err = s.lobbyFor(roomId, func() (*socketStructs.RoomLobby, error) {
+			room, err := s.roomRepo.GetByID(roomId)
+			if err != nil {
+				return nil, err
+			}
+			if room == nil {
+				return nil, fmt.Errorf("room not found")
+			}
+
+			//finished, cancelled o archived
+			if !room.State().Live() && !room.State().NotStarted() {
+				return nil, nil
+			}
+
+			//el dueño o un co_admin abren la sala
+			moderator := user.ID().Id == room.AdminID().Id
+			if !moderator {
+				roles, err := s.roomRepo.GetRoles(roomId)
+				if err != nil {
+					return nil, err
+				}
+				moderator = roles[user.ID().Id] == roomvo.RoleCoAdmin
+			}
+
+			//si la sala ya esta abierta en otra instancia se crea una replica sin cambiar su estado
+			admin := client
+			if !moderator {
+				if !room.State().Live() {
+					return nil, roomerr.ErrUserNotAdmin
+				}
+				admin = nil
+			} else if !room.State().Live() {
+				transition, err := room.ChangeState(roomvo.StateOnline, &userId, "opened by moderator")
+				if err != nil {
+					return nil, err
+				}
+				if err := s.roomRepo.ChangeState(*transition); err != nil {
+					return nil, err
+				}
+			}
+
+			return s.newLobby(room, admin), nil
+		})
 		if err != nil {
 			return err
 		}
-		if room == nil {
-			return fmt.Errorf("room not found")
+		if lobby == nil {
+			return nil
 		}
-		
-		if room.State().CurrentState == "finished" {
+	}
+
+	//el usuario recupera su lugar: no pasa por la lista de espera y recibe la propuesta en curso
+	if reconnect {
+		go client.ReadMessages()
+		go client.WriteMessages()
+		lobby.ReplayTo(client)
+		return nil
+	}
+
+	//sala llena: se rechaza o se encola segun la configuracion. Los observadores no ocupan lugar
+	queue := false
+	if client.User.ID().Id != lobby.Room().AdminID().Id && !lobby.IsObserver(client.User.ID().Id) && lobby.IsFull() {
+		if !lobby.WaitingListEnabled() {
+			ws.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(4003, "La sala esta llena"),
+				time.Now().Add(time.Second),
+			)
 			return nil
 		}
-		
-		if user.ID().Id != room.AdminID().Id {
-			return roomerr.ErrUserNotAdmin
+		queue = true
+	}
+
+	client.SetLobby(lobby)
+
+	go client.ReadMessages()
+	go client.WriteMessages()
+
+	if queue {
+		lobby.Enqueue(client)
+	} else {
+		lobby.AddClient(client)
+	}
+
+	return nil
+}
+
+// devuelve la sala en vivo de esta instancia o la abre con open. roomsmx solo se toma para buscar y registrar
+// la sala, los accesos a la base de open corren fuera del lock. Si otra conexion ya esta abriendo la misma sala
+// se espera a que termine, para no abrirla dos veces. open puede devolver nil sin error si la sala no se abre
+func (s *ManageWsUsecase) lobbyFor(roomId sv.ID, open func() (*socketStructs.RoomLobby, error)) (*socketStructs.RoomLobby, error) {
+	for {
+		s.roomsmx.Lock()
+		if lobby := s.rooms[roomId]; lobby != nil {
+			s.roomsmx.Unlock()
+			return lobby, nil
+		}
+		opening, busy := s.opening[roomId]
+		if !busy {
+			opening = make(chan struct{})
+			s.opening[roomId] = opening
+		}
+		s.roomsmx.Unlock()
+
+		//si la apertura de la otra conexion falla se intenta de nuevo con la propia
+		if busy {
+			<-opening
+			continue
+		}
+
+		lobby, err := open()
+
+		s.roomsmx.Lock()
+		if lobby != nil {
+			s.rooms[roomId] = lobby
+		}
+		delete(s.opening, roomId)
+		close(opening)
+		s.roomsmx.Unlock()
+
+		if lobby != nil {
+			go s.OnEmpty(lobby)
+		}
+		return lobby, err
+	}
+}
+
+// crea la sala en vivo de esta instancia sin registrarla, se registra con lobbyFor
+func (s *ManageWsUsecase) newLobby(room *domain.Room, admin *socketStructs.Client) *socketStructs.RoomLobby {
+	lobby := socketStructs.NewRoomLobby(
+		admin,
+		room,
+		s.roomRepo,
+		s.proposalRepo,
+		s.optionsRepo,
+		s.voteRepo,
+		s.settingRepo,
+		s.delegationRepo,
+		s.userRepo,
+		s.bus,
+	)
+
+	//si el servidor se reinicio con la sala en curso se retoma desde la propuesta guardada
+	if state := room.State().CurrentState; state == roomvo.StateInProgress || state == roomvo.StatePaused {
+		lobbyState, err := s.roomRepo.GetLobbyState(room.ID())
+		if err != nil {
+			log.Printf("error loading state of room id = %d: %v \n", room.ID().Id, err)
+		} else if lobbyState != nil {
+			lobby.Restore(*lobbyState)
+		}
+	}
+
+	return lobby
+}
+
+// suscribe un consumidor de solo lectura a los eventos de la sala. Si la sala esta en vivo
+// en otra instancia se abre una replica sin administrador
+func (s *ManageWsUsecase) Stream(roomId sv.ID) (<-chan socketStructs.Event, func(), error) {
+	lobby, err := s.lobbyFor(roomId, func() (*socketStructs.RoomLobby, error) {
+		room, err := s.roomRepo.GetByID(roomId)
+		if err != nil || room == nil {
+			return nil, roomerr.ErrRoomNotFound
+		}
+
+		if !room.State().Live() {
+			return nil, roomerr.ErrRoomNotLive
+		}
+		return s.newLobby(room, nil), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, unsubscribe := lobby.Subscribe()
+	return events, unsubscribe, nil
+}
+
+// votantes conectados a la sala en todas las instancias. Si la sala esta en vivo en otra instancia
+// se abre una replica, que a partir de ahi sigue la ocupacion por el bus
+func (s *ManageWsUsecase) ConnectedVoters(roomId sv.ID) int {
+	lobby, _ := s.lobbyFor(roomId, func() (*socketStructs.RoomLobby, error) {
+		room, err := s.roomRepo.GetByID(roomId)
+		if err != nil || room == nil || !room.State().Live() {
+			return nil, nil
+		}
+		return s.newLobby(room, nil), nil
+	})
+	if lobby == nil {
+		return 0
+	}
+	return lobby.VoterCount()
+}
+
+// el usuario ya ocupa un lugar en la sala, en esta instancia o en otra
+func (s *ManageWsUsecase) IsConnected(roomId, userId sv.ID) bool {
+	s.roomsmx.RLock()
+	lobby := s.rooms[roomId]
+	s.roomsmx.RUnlock()
+
+	if lobby == nil {
+		return false
+	}
+	for client := range lobby.Clients() {
+		if client.User.ID().Id == userId.Id {
+			return true
 		}
+	}
+	return lobby.ConnectedRemotely(userId.Id)
+}
 
-		room.State().SetState("online")
-		updatedroom, err := s.roomRepo.Update(room)
+// conexiones de la sala en esta instancia, solo para el dueño y los co_admin
+func (s *ManageWsUsecase) Health(roomId, userId sv.ID) (*domain.LobbyHealth, error) {
+	room, err := s.roomRepo.GetByID(roomId)
+	if err != nil || room == nil {
+		return nil, roomerr.ErrRoomNotFound
+	}
 
+	if room.AdminID().Id != userId.Id {
+		roles, err := s.roomRepo.GetRoles(roomId)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		if roles[userId.Id] != roomvo.RoleCoAdmin {
+			return nil, roomerr.ErrUserNotAdmin
+		}
+	}
 
-		s.rooms[roomId] = socketStructs.NewRoomLobby(
-			client,
-			updatedroom,
-			s.roomRepo,
-			s.proposalRepo,
-			s.optionsRepo,
-			s.voteRepo,
-		)
+	s.roomsmx.RLock()
+	lobby := s.rooms[roomId]
+	s.roomsmx.RUnlock()
 
-		go s.OnEmpty(s.rooms[roomId])
+	//la sala no esta en vivo en esta instancia
+	if lobby == nil {
+		return &domain.LobbyHealth{RoomID: roomId.Id}, nil
 	}
 
-	client.SetLobby(s.rooms[roomId])
+	health := lobby.Health()
+	return &health, nil
+}
 
-	go client.ReadMessages()
-	go client.WriteMessages()
+// la sala en vivo vuelve a cargar los roles, en esta instancia y en las demas
+func (s *ManageWsUsecase) RolesChanged(roomId sv.ID) {
+	s.roomsmx.RLock()
+	lobby := s.rooms[roomId]
+	s.roomsmx.RUnlock()
 
-	s.rooms[roomId].AddClient(client)
+	if lobby != nil {
+		lobby.ReloadRoles()
+	}
 
-	return nil
+	if s.bus != nil {
+		err := s.bus.Publish(domain.BusMessage{RoomID: roomId.Id, Origin: s.bus.InstanceID(), Kind: socketStructs.BusRoles})
+		if err != nil {
+			log.Printf("error publishing roles of room id = %d: %v \n", roomId.Id, err)
+		}
+	}
+}
+
+// la sala se cierra en esta instancia y se avisa a las demas. Si no esta en vivo solo se cambia su estado,
+// queda finished si la votacion empezo y cancelled si no
+func (s *ManageWsUsecase) CloseLobby(roomId sv.ID, reason string) {
+	s.roomsmx.RLock()
+	lobby := s.rooms[roomId]
+	s.roomsmx.RUnlock()
+
+	if lobby != nil {
+		lobby.Close(reason)
+	} else if room, err := s.roomRepo.GetByID(roomId); err == nil && room != nil {
+		transition, err := room.ChangeState(room.State().ClosedState(), nil, reason)
+		if err == nil {
+			err = s.roomRepo.ChangeState(*transition)
+		}
+		if err != nil {
+			log.Printf("error closing room id = %d: %v \n", roomId.Id, err)
+		}
+	}
+
+	if s.bus != nil {
+		payload, _ := json.Marshal(reason)
+		msg := domain.BusMessage{RoomID: roomId.Id, Origin: s.bus.InstanceID(), Kind: socketStructs.BusClose, Payload: payload}
+		if err := s.bus.Publish(msg); err != nil {
+			log.Printf("error publishing close of room id = %d: %v \n", roomId.Id, err)
+		}
+	}
 }
 
 func (s *ManageWsUsecase) OnEmpty(room *socketStructs.RoomLobby) {
 	<-room.Empty
+	s.roomsmx.Lock()
 	delete(s.rooms, room.Room().ID())
+	s.roomsmx.Unlock()
 	log.Printf("Room instance cleared id = %d \n", room.Room().ID().Id)
 }
@@ -3,23 +3,35 @@ package socketStructs
 import (
 	"encoding/json"
 	"log"
+	"time"
 
 	userdom "suffgo/internal/users/domain"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
-	conn      *websocket.Conn
-	User      userdom.User
-	lobby     *RoomLobby
-	voted     bool
-	egress    chan Event
-	done      chan struct{}
-	errorSent chan struct{}
+	conn  *websocket.Conn
+	User  userdom.User
+	lobby *RoomLobby
+	voted bool
+	//true si el usuario esta en la whitelist de la sala (user_room)
+	whitelisted bool
+	egress      chan Event
+	done        chan struct{}
+	errorSent   chan struct{}
+
+	resumeToken string        //permite retomar el lugar en la sala despues de un corte
+	detached    chan struct{} //se cierra cuando se pierde la conexion actual
+	grace       *time.Timer   //vence el lugar reservado si no se reconecta
+
+	heartbeat Heartbeat
+	health    connHealth
+	version   int //version del protocolo negociada al conectarse
 }
 
-func NewClient(conn *websocket.Conn, user userdom.User) *Client {
+func NewClient(conn *websocket.Conn, user userdom.User, heartbeat Heartbeat) *Client {
 	return &Client{
 		conn:      conn,
 		User:      user,
@@ -27,18 +39,44 @@ func NewClient(conn *websocket.Conn, user userdom.User) *Client {
 		egress:    make(chan Event),
 		errorSent: make(chan struct{}),
 		done:      make(chan struct{}),
+
+		resumeToken: uuid.New().String(),
+		detached:    make(chan struct{}),
+
+		heartbeat: heartbeat,
+		version:   ProtocolV1,
 	}
 }
 
 func (c *Client) ReadMessages() {
+	conn := c.conn
+	c.health.reset()
+
+	//cada pong extiende el plazo de lectura, sin pong la lectura falla por timeout
+	conn.SetReadDeadline(time.Now().Add(c.heartbeat.PongWait))
+	conn.SetPongHandler(func(string) error {
+		c.health.ponged()
+		return conn.SetReadDeadline(time.Now().Add(c.heartbeat.PongWait))
+	})
+
 	for {
-		_, payload, err := c.conn.ReadMessage()
+		_, payload, err := conn.ReadMessage()
 
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("error ws message: %v \n", err)
 			}
-			c.lobby.removeClient(c)
+			//si el usuario cerro la sala se libera su lugar, cualquier otro corte espera la reconexion.
+			//Una conexion que no responde el ping se cierra, pero el usuario conserva su lugar
+			if isTimeout(err) && c.Conn() == conn {
+				log.Printf("user %s did not answer ping, closing connection \n", c.User.Username().Username)
+				c.lobby.reaped.Add(1)
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) && c.Conn() == conn {
+				c.lobby.removeClient(c)
+			} else {
+				c.lobby.disconnectClient(c, conn)
+			}
 			return
 		}
 
@@ -58,24 +96,43 @@ func (c *Client) ReadMessages() {
 }
 
 func (c *Client) WriteMessages() {
+	conn := c.conn
+	detached := c.detached
+
+	ticker := time.NewTicker(c.heartbeat.PingInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(c.heartbeat.WriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Printf("failed to send ping: %v", err)
+				continue
+			}
+			c.health.pinged()
+
 		case message, ok := <-c.egress:
 			if !ok {
-				if err := c.conn.WriteMessage(websocket.CloseMessage, nil); err != nil {
+				conn.SetWriteDeadline(time.Now().Add(c.heartbeat.WriteWait))
+				if err := conn.WriteMessage(websocket.CloseMessage, nil); err != nil {
 					log.Println("connection closed", err)
 				}
 				return
 			}
 
+			if c.version >= ProtocolV2 {
+				message.Version = c.version
+			}
+
 			data, err := json.Marshal(message)
 			if err != nil {
 				log.Println(err)
 				return
 			}
 
-			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			conn.SetWriteDeadline(time.Now().Add(c.heartbeat.WriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
 				log.Printf("failed to send message: %v", err)
 			}
 
@@ -86,6 +143,9 @@ func (c *Client) WriteMessages() {
 				c.errorSent <- struct{}{}
 			}
 
+		case <-detached:
+			return
+
 		case <-c.done:
 			close(c.egress)
 			return
@@ -93,6 +153,14 @@ func (c *Client) WriteMessages() {
 	}
 }
 
+func (c *Client) Version() int {
+	return c.version
+}
+
+func (c *Client) SetVersion(version int) {
+	c.version = version
+}
+
 func (c *Client) Conn() *websocket.Conn {
 	return c.conn
 }
@@ -101,6 +169,10 @@ func (c *Client) SetConn(conn *websocket.Conn) {
 	c.conn = conn
 }
 
+func (c *Client) ResumeToken() string {
+	return c.resumeToken
+}
+
 func (c *Client) Lobby() *RoomLobby {
 	return c.lobby
 }
@@ -3,11 +3,15 @@ package socketStructs
 import (
 	"encoding/json"
 	optdom "suffgo/internal/options/domain"
+	propdom "suffgo/internal/proposals/domain"
+	"time"
 )
 
 type Event struct {
 	Action  string          `json:"action"`
 	Payload json.RawMessage `json:"payload"`
+	ID      string          `json:"id,omitempty"`      //v2: id elegido por el cliente, se repite en el ack o nack
+	Version int             `json:"version,omitempty"` //v2: version negociada al conectarse
 }
 
 type EventUsecase func(event Event, c *Client) error
@@ -24,15 +28,83 @@ const (
 	EventError            = "error"
 	EventKickUser         = "kick_user"
 	EventKickInfoUser     = "kick_info"
+	EventTimerTick        = "timer_tick"
+	EventWaitingList      = "waiting_list"
+	EventSession          = "session"
+	EventResume           = "resume"
+	EventAck              = "ack"
+	EventNack             = "nack"
+	EventMuteUser         = "mute_user"
+	EventUnmuteUser       = "unmute_user"
+	EventDeleteMessage    = "delete_message"
+	EventChatHistory      = "chat_history"
+	EventRoomClosed       = "room_closed"
+	EventPauseVoting      = "pause_voting"
+	EventResumeVoting     = "resume_voting"
+	EventRevote           = "revote"
 )
 
-type SendMessageEvent struct {
+// mensaje que envia el cliente, el remitente lo asigna el servidor
+type NewMessageEvent struct {
 	Message string `json:"message"`
-	From    string `json:"from"`
+}
+
+type SendMessageEvent struct {
+	ID      uint      `json:"id"`
+	Message string    `json:"message"`
+	From    string    `json:"from"` //username del remitente
+	UserID  uint      `json:"user_id"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// ultimos mensajes de la sala, se envia al entrar
+type ChatHistoryEvent struct {
+	Messages []SendMessageEvent `json:"messages"`
+}
+
+type MuteUserEvent struct {
+	UserId   uint `json:"user_id"`
+	Duration int  `json:"duration"` //segundos, 0 hasta que se lo vuelva a habilitar
+}
+
+type MutedEvent struct {
+	UserId uint       `json:"user_id"`
+	Muted  bool       `json:"muted"`
+	Until  *time.Time `json:"until,omitempty"` //nil si no tiene vencimiento
+}
+
+// la sala se cerro sin pasar por la votacion, la conexion se cierra despues de este evento
+type RoomClosedEvent struct {
+	Reason string `json:"reason"`
+}
+
+type PauseVotingEvent struct {
+	Reason string `json:"reason"` //opcional, se guarda en el historial de la sala
+}
+
+// se envia con pause_voting o resume_voting y al entrar a una sala pausada
+type PausedEvent struct {
+	Paused     bool   `json:"paused"`
+	Reason     string `json:"reason,omitempty"`
+	ProposalID uint   `json:"proposal_id"` //propuesta en curso, 0 si la votacion no empezo
+	Remaining  int    `json:"remaining"`   //segundos de la cuenta regresiva congelados, 0 si no hay limite
+}
+
+type DeleteMessageEvent struct {
+	MessageId uint `json:"message_id"`
 }
 
 type UpdateClientListEvent struct {
-	Clients []ClientData `json:"clients"`
+	Clients   []ClientData `json:"clients"`
+	Observers []ClientData `json:"observers"` //no votan ni cuentan para el quorum
+	Quorum    QuorumStatus `json:"quorum"`
+}
+
+type QuorumStatus struct {
+	Required  int  `json:"required"`
+	Present   int  `json:"present"`
+	Whitelist int  `json:"whitelist"`
+	Reached   bool `json:"reached"`
 }
 
 // estructura utilizada para trackear votos en tiempo real
@@ -44,9 +116,35 @@ type ClientData struct {
 	Email    string `json:"email"`
 	Voted    bool   `json:"voted"`
 	Image    string `json:"image"`
+	//false mientras el usuario esta en el periodo de reconexion
+	Connected bool   `json:"connected"`
+	Role      string `json:"role"` //owner, co_admin, voter u observer
+
+	Represents      []RepresentedData `json:"represents,omitempty"`
+	DelegatedWeight int               `json:"delegated_weight"` //suma de los pesos de los usuarios que representa
+}
+
+// usuario que delego su voto en el cliente
+type RepresentedData struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Voted    bool   `json:"voted"`
 }
 
 type ErrorEvent struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// confirma un evento recibido con id
+type AckEvent struct {
+	Action string `json:"action"`
+}
+
+// rechaza un evento recibido con id, code es uno de los ErrCode
+type NackEvent struct {
+	Action  string `json:"action"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
@@ -58,6 +156,20 @@ type ProposalEvent struct {
 	RoomID      uint               `json:"room_id"`
 	Options     []optdom.OptionDTO `json:"options"`
 	LastProp    bool               `json:"last_prop"`
+	Duration    int                `json:"duration"` //segundos para votar, 0 si no hay limite
+	BallotType  string             `json:"ballot_type"`
+	MinSelect   int                `json:"min_selections"`
+	MaxSelect   int                `json:"max_selections"`
+	Rule        string             `json:"decision_rule"`
+	Abstentions string             `json:"abstentions"`
+	VoteChange  bool               `json:"vote_change"` //true si se puede cambiar la boleta hasta que cierre la propuesta
+	Round       int                `json:"round"`       //1 salvo en las re-votaciones
+}
+
+// vuelve a abrir la propuesta en curso en una ronda nueva
+type RevoteEvent struct {
+	Top    int    `json:"top"`    //solo las top opciones mas votadas de la ronda anterior, 0 para todas
+	Reason string `json:"reason"` //opcional, se guarda con la ronda
 }
 
 type NextPropEvent struct {
@@ -65,12 +177,21 @@ type NextPropEvent struct {
 }
 
 type VoteEvent struct {
-	OptionId uint `json:"option_id"`
+	OptionId   uint   `json:"option_id"`
+	Ranking    []uint `json:"ranking"`      //solo propuestas ranked, ids ordenados por preferencia
+	OnBehalfOf uint   `json:"on_behalf_of"` //id del usuario representado, 0 si es el voto propio
+	Options    []uint `json:"options"`      //solo propuestas approval y multi
+	Choice     string `json:"choice"`       //"abstain" o "blank" para no elegir opcion, vacio para votar opciones
 }
 
 type UserVoteEvent struct {
-	From     VoterData `json:"from"`
-	OptionId uint      `json:"option_id"`
+	From     VoterData  `json:"from"`
+	OptionId uint       `json:"option_id"`
+	Ranking  []uint     `json:"ranking,omitempty"`
+	Weight   int        `json:"weight"`
+	Proxy    *VoterData `json:"proxy,omitempty"` //delegado que emitio el voto
+	Options  []uint     `json:"options,omitempty"`
+	Choice   string     `json:"choice"`
 }
 
 type VoterData struct {
@@ -79,9 +200,51 @@ type VoterData struct {
 }
 
 type ResultsEvent struct {
-	Votes []UserVoteEvent `json:"votes"`
+	Votes     []UserVoteEvent       `json:"votes"`
+	Valid     bool                  `json:"valid"` //false si no se alcanzo el quorum de votos
+	Cast      int                   `json:"cast"`
+	Required  int                   `json:"required"`
+	Runoff    *propdom.RunoffResult `json:"runoff,omitempty"`
+	Approvals []propdom.OptionTally `json:"approvals,omitempty"` //suma de pesos por opcion
+	Secret    bool                  `json:"secret"`
+	Outcome   *propdom.Outcome      `json:"outcome,omitempty"`
+	Abstain   propdom.ChoiceResults `json:"abstain"`
+	Blank     propdom.ChoiceResults `json:"blank"`
+	Round     int                   `json:"round"`
 }
 
 type KickUserEvent struct {
 	UserId uint `json:"user_id"`
 }
+
+type WaitingListEvent struct {
+	Position int  `json:"position"` //posicion en la cola, 0 cuando fue admitido
+	Admitted bool `json:"admitted"`
+}
+
+type TimerTickEvent struct {
+	ProposalID uint `json:"proposal_id"`
+	Remaining  int  `json:"remaining"`
+}
+
+type EndVotingEvent struct {
+	ProposalID uint   `json:"proposal_id"`
+	Reason     string `json:"reason"`
+}
+
+// se envia al entrar a la sala, el token se usa para reconectarse sin perder el lugar
+type SessionEvent struct {
+	ResumeToken string `json:"resume_token"`
+	Grace       int    `json:"grace"`   //segundos que se reserva el lugar despues de un corte
+	Version     int    `json:"version"` //version del protocolo negociada
+}
+
+// estado de la sala que se reenvia al reconectarse
+type ResumeEvent struct {
+	ResumeToken string         `json:"resume_token"`
+	Proposal    *ProposalEvent `json:"proposal,omitempty"` //propuesta actual, nil si la votacion no empezo
+	VotingOpen  bool           `json:"voting_open"`
+	Remaining   int            `json:"remaining"` //segundos restantes de la cuenta regresiva, 0 si no hay limite
+	Voted       bool           `json:"voted"`
+	Paused      bool           `json:"paused"`
+}
@@ -2,14 +2,22 @@ package socketStructs
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 
+	dldom "suffgo/internal/delegations/domain"
 	optdom "suffgo/internal/options/domain"
 	propdom "suffgo/internal/proposals/domain"
 	"suffgo/internal/rooms/domain"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	srdom "suffgo/internal/settingsRoom/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	userdom "suffgo/internal/users/domain"
 
 	votedom "suffgo/internal/votes/domain"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ClientList map[*Client]bool
@@ -17,45 +25,116 @@ type ClientList map[*Client]bool
 type RoomLobby struct {
 	sync.RWMutex
 	clientsmx      sync.RWMutex
-	votesProcesing chan struct{} 
+	votingmx       sync.RWMutex
+	votesProcesing chan struct{}
 	Empty          chan struct{}
 
-	clients      ClientList
-	admin        *Client
-	room         *domain.Room
-	proposals    []propdom.Proposal
-	propRepo     propdom.ProposalRepository
-	roomRepo     domain.RoomRepository
-	optRepo      optdom.OptionRepository
-	voteRepo     votedom.VoteRepository
-	usecases     map[string]EventUsecase
-	results      map[*Client]votedom.Vote
-	nextProposal int
+	clients         ClientList
+	waiting         []*Client //cola de espera cuando se alcanza VoterLimit
+	admin           *Client
+	room            *domain.Room
+	settings        *srdom.SettingRoom
+	whitelistSize   int
+	whitelistWeight int //peso total de la whitelist, base de la mayoria absoluta
+	proposals       []propdom.Proposal
+	propRepo        propdom.ProposalRepository
+	roomRepo        domain.RoomRepository
+	userRepo        userdom.UserRepository
+	optRepo         optdom.OptionRepository
+	voteRepo        votedom.VoteRepository
+	usecases        map[string]EventUsecase
+	results         map[uint]castBallot //boletas de la propuesta actual por id de votante
+	session         sv.ID               //sesion de votacion activa, recibe los votos de la sala
+	nextProposal    int
+
+	votingOpen     bool
+	votingProposal uint
+	timer          *proposalTimer
+	remaining      int    //segundos restantes de la cuenta regresiva, se conserva al pausar la sala
+	round          int    //ronda de la propuesta en curso, mayor a 1 en las re-votaciones
+	roundOptions   []uint //opciones que se votan en la ronda, nil si se votan todas
+
+	represented     map[uint][]VoterData //delegado -> usuarios que representa
+	delegatedTo     map[uint]uint        //delegador -> delegado
+	delegatedWeight map[uint]int         //delegado -> suma de pesos que representa
+
+	roles map[uint]string //co_admin y observer, protegido por el RWMutex de la sala
+
+	reaped atomic.Int64 //conexiones cerradas por no responder el ping
+
+	bus         domain.LobbyBus
+	remote      map[string]map[uint]remoteClient //instancia -> clientes conectados a ella
+	unsubscribe func()
+
+	streamsmx     sync.Mutex
+	streams       streamList //consumidores SSE de la sala
+	streamsClosed bool
+
+	chatmx   sync.Mutex
+	chat     []SendMessageEvent   //ultimos mensajes del chat
+	muted    map[uint]*time.Time  //usuarios silenciados, nil si no vence
+	chatSent map[uint][]time.Time //envios recientes por usuario para el limite de mensajes
 }
 
-func NewRoomLobby(admin *Client, room *domain.Room, roomRepo domain.RoomRepository, propRepo propdom.ProposalRepository, optRepo optdom.OptionRepository, voteRepo votedom.VoteRepository) *RoomLobby {
+func NewRoomLobby(admin *Client, room *domain.Room, roomRepo domain.RoomRepository, propRepo propdom.ProposalRepository, optRepo optdom.OptionRepository, voteRepo votedom.VoteRepository, settingRepo srdom.SettingRoomRepository, delegationRepo dldom.DelegationRepository, userRepo userdom.UserRepository, bus domain.LobbyBus) *RoomLobby {
 
 	//error ya manejado anteriormente
 	proposals, _ := propRepo.GetByRoom(room.ID())
 
+	//si la sala no tiene configuracion se vota sin limite de tiempo
+	settings, err := settingRepo.GetByRoom(room.ID())
+	if err != nil {
+		log.Printf("room id = %d has no settings: %v \n", room.ID().Id, err)
+		settings = nil
+	}
+
+	whitelistSize, err := roomRepo.CountWhitelist(room.ID())
+	if err != nil {
+		log.Printf("error counting whitelist of room id = %d: %v \n", room.ID().Id, err)
+	}
+
+	whitelistWeight, err := roomRepo.WhitelistTotalWeight(room.ID())
+	if err != nil {
+		log.Printf("error summing whitelist weight of room id = %d: %v \n", room.ID().Id, err)
+	}
+
+	//los votos de la sala en vivo se guardan en la sesion activa
+	var sessionID sv.ID
+	session, err := roomRepo.ActiveSession(room.ID())
+	if err != nil {
+		log.Printf("error loading voting session of room id = %d: %v \n", room.ID().Id, err)
+	} else {
+		sessionID.Id = session.ID
+	}
+
 	r := &RoomLobby{
-		clients:        make(ClientList),
-		admin:          admin,
-		room:           room,
-		usecases:       make(map[string]EventUsecase),
-		proposals:      proposals,
-		roomRepo:       roomRepo,
-		propRepo:       propRepo,
-		optRepo:        optRepo,
-		voteRepo:       voteRepo,
-		results:        make(map[*Client]votedom.Vote),
-		votesProcesing: make(chan struct{}, 1),
-		nextProposal:   0,
-		Empty:          make(chan struct{}, 1),
+		clients:         make(ClientList),
+		admin:           admin,
+		room:            room,
+		settings:        settings,
+		whitelistSize:   whitelistSize,
+		whitelistWeight: whitelistWeight,
+		usecases:        make(map[string]EventUsecase),
+		proposals:       proposals,
+		roomRepo:        roomRepo,
+		userRepo:        userRepo,
+		propRepo:        propRepo,
+		optRepo:         optRepo,
+		voteRepo:        voteRepo,
+		results:         make(map[uint]castBallot),
+		session:         sessionID,
+		votesProcesing:  make(chan struct{}, 1),
+		nextProposal:    0,
+		round:           1,
+		Empty:           make(chan struct{}, 1),
 	}
 
+	r.loadRoles()
+	r.loadChat()
+	r.loadDelegations(delegationRepo, userRepo)
 	r.initializeUsecases()
 	r.votesProcesing <- struct{}{}
+	r.joinBus(bus)
 
 	return r
 }
@@ -67,16 +146,28 @@ func (r *RoomLobby) initializeUsecases() {
 	r.usecases[EventResults] = SendResults
 	r.usecases[EventNextProp] = NextProposal
 	r.usecases[EventKickUser] = KickUser
+	r.usecases[EventMuteUser] = MuteUser
+	r.usecases[EventUnmuteUser] = UnmuteUser
+	r.usecases[EventDeleteMessage] = DeleteMessage
+	r.usecases[EventPauseVoting] = PauseVoting
+	r.usecases[EventResumeVoting] = ResumeVoting
+	r.usecases[EventRevote] = Revote
 }
 
 func (r *RoomLobby) routeEvent(event Event, c *Client) error {
-	if usecase, ok := r.usecases[event.Action]; ok {
-		if err := usecase(event, c); err != nil {
-			return err
-		}
-		return nil
+	//los usuarios en lista de espera no pueden interactuar con la sala
+	r.clientsmx.RLock()
+	_, active := r.clients[c]
+	r.clientsmx.RUnlock()
+	if !active {
+		return c.reply(event, newProtocolError(ErrCodeNotAdmitted, "you are in the waiting list"))
 	}
-	return nil
+
+	usecase, ok := r.usecases[event.Action]
+	if !ok {
+		return c.reply(event, newProtocolError(ErrCodeUnknownAction, fmt.Sprintf("unknown action %q", event.Action)))
+	}
+	return c.reply(event, usecase(event, c))
 }
 
 func (r *RoomLobby) Admin() *Client {
@@ -86,10 +177,26 @@ func (r *RoomLobby) Admin() *Client {
 	return r.admin
 }
 
+// debe llamarse con clientsmx tomado
 func (r *RoomLobby) broadcastClientList() {
+	event := r.clientListEvent()
+
+	for client := range r.clients {
+		if r.clients[client] {
+			client.egress <- event
+		}
+
+	}
+	r.stream(event)
+}
+
+// debe llamarse con clientsmx tomado
+func (r *RoomLobby) clientListEvent() Event {
 	// 1. Recorremos los clientes activos para obtener sus nombres (o información requerida).
 	var clients []ClientData
+	var observers []ClientData
 	for client := range r.clients {
+		represented, delegatedWeight := r.representedData(client.User.ID().Id)
 		clientData := ClientData{
 			ID:       client.User.ID().Id,
 			Name:     client.User.FullName().Name,
@@ -98,27 +205,64 @@ func (r *RoomLobby) broadcastClientList() {
 			Email:    client.User.Email().Email,
 			Voted:    client.voted,
 			Image:    client.User.Image().URL(),
+
+			Connected:       client.conn != nil,
+			Role:            r.role(client.User.ID().Id),
+			Represents:      represented,
+			DelegatedWeight: delegatedWeight,
 		}
 
+		//los observadores se listan aparte
+		if clientData.Role == v.RoleObserver {
+			observers = append(observers, clientData)
+			continue
+		}
 		clients = append(clients, clientData)
 	}
 
+	//clientes conectados a otras instancias
+	for _, remotes := range r.remote {
+		for _, remote := range remotes {
+			clientData := remote.Client
+			clientData.Role = r.role(clientData.ID)
+			if clientData.Role == v.RoleObserver {
+				observers = append(observers, clientData)
+				continue
+			}
+			clientData.Represents, clientData.DelegatedWeight = r.representedData(clientData.ID)
+			clients = append(clients, clientData)
+		}
+	}
+
 	// 2. Creamos el evento con la acción y el payload correspondiente.
 	updateEventData := UpdateClientListEvent{
-		Clients: clients,
+		Clients:   clients,
+		Observers: observers,
+		Quorum:    r.quorumStatus(),
 	}
 
-	event := Event{
+	return Event{
 		Action:  EventUpdateClientList,
 		Payload: marshalOrPanic(updateEventData),
 	}
+}
+
+// envia el evento a todos los clientes conectados, tambien a los de otras instancias
+func (r *RoomLobby) broadcast(event Event) {
+	r.broadcastLocal(event)
+	r.publish(busEvent, event)
+}
+
+func (r *RoomLobby) broadcastLocal(event Event) {
+	r.clientsmx.RLock()
+	defer r.clientsmx.RUnlock()
 
 	for client := range r.clients {
-		if r.clients[client] {
+		if client.conn != nil {
 			client.egress <- event
 		}
-
 	}
+	r.stream(event)
 }
 
 func marshalOrPanic(v interface{}) []byte {
@@ -130,35 +274,131 @@ func marshalOrPanic(v interface{}) []byte {
 }
 
 func (r *RoomLobby) AddClient(client *Client) {
+	r.prepareClient(client)
+
 	r.clientsmx.Lock()
 	defer r.clientsmx.Unlock()
 
+	r.admit(client)
+}
+
+// datos del cliente que dependen de la sala. Se cargan antes de entrar o de encolarse porque
+// no pueden leerse con clientsmx tomado
+func (r *RoomLobby) prepareClient(client *Client) {
+	whitelisted, err := r.roomRepo.UserInWhitelist(r.room.ID(), client.User.ID())
+	if err != nil {
+		log.Printf("error checking whitelist: %v \n", err)
+	}
+	client.whitelisted = whitelisted
+
+	//si ya voto la propuesta actual (ej: se reconecto) conserva su estado
+	<-r.votesProcesing
+	_, client.voted = r.results[client.User.ID().Id]
+	r.votesProcesing <- struct{}{}
+}
+
+// suma el cliente a los conectados y avisa al resto, tambien a los admitidos desde la lista de espera.
+// Debe llamarse con clientsmx tomado
+func (r *RoomLobby) admit(client *Client) {
 	r.clients[client] = true //lo agrego a la lista de clientes conectados
+	r.sendSession(client)
 	for user, conn := range r.clients {
 		log.Printf("user %s; conn: %t", user.User.Username().Username, conn)
 	}
 
 	r.broadcastClientList()
+	r.publishPresence(client, false)
+}
+
+// la propuesta o ronda nueva empieza sin boletas: nadie voto todavia
+func (r *RoomLobby) clearBallots() {
+	<-r.votesProcesing
+	r.results = make(map[uint]castBallot)
+	r.votesProcesing <- struct{}{}
 
+	r.clientsmx.Lock()
+	defer r.clientsmx.Unlock()
+
+	for client := range r.clients {
+		client.voted = false
+	}
+	for _, queued := range r.waiting {
+		queued.voted = false
+	}
+	for _, clients := range r.remote {
+		for id, remote := range clients {
+			remote.Client.Voted = false
+			clients[id] = remote
+		}
+	}
+	r.broadcastClientList()
 }
 
 func (r *RoomLobby) removeClient(client *Client) {
 	r.clientsmx.Lock()
 	if _, ok := r.clients[client]; ok {
-		client.conn.Close()
+		//un cliente desconectado no tiene conexion abierta
+		if client.conn != nil {
+			client.conn.Close()
+		}
+		if client.grace != nil {
+			client.grace.Stop()
+		}
 		delete(r.clients, client)
 		close(client.done)
+	} else if r.dequeue(client) {
+		client.conn.Close()
+		close(client.done)
+		r.notifyQueuePositions()
+		r.clientsmx.Unlock()
+		return
 	}
+	r.admitFromQueue()
 	r.clientsmx.Unlock()
 
+	r.clientsmx.RLock()
 	r.broadcastClientList()
+	localEmpty := len(r.clients) == 0
+	remoteEmpty := r.remoteCount() == 0
+	r.clientsmx.RUnlock()
+	r.publishPresence(client, true)
+
+	//quedan usuarios en otras instancias: solo se descarta esta replica de la sala
+	if localEmpty && !remoteEmpty {
+		r.votingmx.Lock()
+		r.stopTimerLocked()
+		r.votingmx.Unlock()
+		r.leaveBus()
+		r.closeStreams()
+		r.Empty <- struct{}{}
+		return
+	}
+
+	if localEmpty && r.room.State().Live() {
+		r.votingmx.Lock()
+		r.votingOpen = false
+		r.stopTimerLocked()
+		r.votingmx.Unlock()
 
-	state := r.room.State().CurrentState
-	if len(r.clients) == 0 && (state == "in progress" || state == "online") {
-		r.ChangeRoomState("created")
+		r.clientsmx.Lock()
+		for _, queued := range r.waiting {
+			queued.conn.Close()
+			close(queued.done)
+		}
+		r.waiting = nil
+		r.clientsmx.Unlock()
+
+		//si la votacion empezo, el proximo intento se vota en una sesion nueva y esta queda archivada
+		started := !r.room.State().NotStarted() && r.room.State().CurrentState != v.StateOnline
+		r.ChangeRoomState(v.StateCreated, nil, "all users left")
+		r.leaveBus()
+		r.closeStreams()
 		r.Empty <- struct{}{}
-		//Reiniciar votos cuando la sala es reiniciada para no afectar resultados finales
-		client.lobby.roomRepo.RestartRoom(r.room.ID())
+		if started {
+			if _, err := r.roomRepo.NewSession(r.room.ID()); err != nil {
+				log.Printf("error opening voting session of room id = %d: %v \n", r.room.ID().Id, err)
+			}
+		}
 	}
 }
 
@@ -172,12 +412,39 @@ func (r *RoomLobby) Room() *domain.Room {
 	return r.room
 }
 
-func (r *RoomLobby) ChangeRoomState(state string) *domain.Room {
-	r.room.State().SetState(state)
-	_, err := r.roomRepo.Update(r.room)
-	if err != nil {
-		log.Println("Error updating room state")
+// valida el cambio de estado, lo guarda en el historial y lo publica a las demas instancias.
+// by es nil si el cambio lo hace el sistema
+func (r *RoomLobby) ChangeRoomState(state string, by *Client, reason string) error {
+	if r.room.State().CurrentState == state {
 		return nil
 	}
+
+	var actorID *sv.ID
+	if by != nil {
+		id := by.User.ID()
+		actorID = &id
+	}
+
+	from := r.room.State().CurrentState
+	transition, err := r.room.ChangeState(state, actorID, reason)
+	if err == nil {
+		err = r.roomRepo.ChangeState(*transition)
+		if err != nil {
+			r.setLocalState(from)
+		}
+	}
+	if err != nil {
+		log.Printf("error changing state of room id = %d: %v \n", r.room.ID().Id, err)
+		return err
+	}
+
+	r.publishState()
 	return nil
-}
\ No newline at end of file
+}
+
+// cambia el estado en memoria sin validarlo ni guardarlo, el cambio lo hizo otra instancia
+func (r *RoomLobby) setLocalState(value string) {
+	if state, err := v.NewState(value); err == nil {
+		*r.room.State() = *state
+	}
+}
@@ -0,0 +1,130 @@
+package socketStructs
+
+import (
+	"errors"
+	propdom "suffgo/internal/proposals/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	votedom "suffgo/internal/votes/domain"
+)
+
+// valida el ranking recibido y arma una fila de voto por opcion con su posicion
+func (r *RoomLobby) rankedBallot(proposalID sv.ID, userID sv.ID, ranking []uint) ([]votedom.Vote, error) {
+	if len(ranking) == 0 {
+		return nil, errors.New("ranking is empty")
+	}
+
+	ballot, err := r.buildBallot(proposalID, userID, ranking)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ballot {
+		ballot[i].SetRank(i + 1)
+	}
+
+	return ballot, nil
+}
+
+// valida las opciones elegidas en boletas approval/multi contra los limites de la propuesta
+func (r *RoomLobby) selectionBallot(proposal *propdom.Proposal, userID sv.ID, selected []uint) ([]votedom.Vote, error) {
+	if err := proposal.BallotType().ValidateSelections(len(selected)); err != nil {
+		return nil, err
+	}
+
+	return r.buildBallot(proposal.ID(), userID, selected)
+}
+
+// una fila de voto por opcion, todas deben pertenecer a la propuesta y no repetirse
+func (r *RoomLobby) buildBallot(proposalID sv.ID, userID sv.ID, selected []uint) ([]votedom.Vote, error) {
+	options, err := r.optRepo.GetByProposal(proposalID)
+	if err != nil {
+		return nil, errors.New("error fetching options")
+	}
+
+	valid := make(map[uint]bool, len(options))
+	for _, option := range options {
+		valid[option.ID().Id] = true
+	}
+
+	//en las re-votaciones restringidas solo se votan las opciones de la ronda
+	if _, roundOptions := r.currentRound(); roundOptions != nil {
+		valid = make(map[uint]bool, len(roundOptions))
+		for _, optionID := range roundOptions {
+			valid[optionID] = true
+		}
+	}
+
+	seen := make(map[uint]bool, len(selected))
+	ballot := make([]votedom.Vote, 0, len(selected))
+	for _, optionID := range selected {
+		if !valid[optionID] {
+			return nil, errors.New("ballot contains an invalid option")
+		}
+		if seen[optionID] {
+			return nil, errors.New("ballot contains duplicated options")
+		}
+		seen[optionID] = true
+
+		optID, err := sv.NewID(optionID)
+		if err != nil {
+			return nil, err
+		}
+
+		ballot = append(ballot, *votedom.NewVote(nil, &userID, optID))
+	}
+
+	return ballot, nil
+}
+
+// persiste la boleta, en salas con voto secreto se guarda sin vincular al usuario
+func (r *RoomLobby) saveBallot(proposal *propdom.Proposal, userID sv.ID, ballot []votedom.Vote) ([]votedom.Vote, error) {
+	//se guarda el peso vigente al momento de votar
+	weight, err := r.roomRepo.WhitelistWeight(r.room.ID(), userID)
+	if err != nil {
+		return nil, err
+	}
+	round, _ := r.currentRound()
+	for i := range ballot {
+		ballot[i].SetWeight(weight)
+		ballot[i].SetSessionID(&r.session)
+		ballot[i].SetRound(round)
+	}
+
+	if r.isSecret() && proposal != nil {
+		return r.voteRepo.SaveSecretBallot(userID, proposal.ID(), r.session, round, ballot)
+	}
+
+	if len(ballot) == 1 {
+		saved, err := r.voteRepo.Save(ballot[0])
+		if err != nil {
+			return nil, err
+		}
+		return []votedom.Vote{*saved}, nil
+	}
+
+	return r.voteRepo.SaveBallot(ballot)
+}
+
+// reemplaza la boleta ya emitida conservando el peso con el que se voto originalmente
+func (r *RoomLobby) changeBallot(previous []votedom.Vote, ballot []votedom.Vote) ([]votedom.Vote, error) {
+	weight := 1
+	if len(previous) > 0 {
+		weight = previous[0].Weight()
+	}
+	round, _ := r.currentRound()
+	for i := range ballot {
+		ballot[i].SetWeight(weight)
+		ballot[i].SetSessionID(&r.session)
+		ballot[i].SetRound(round)
+	}
+
+	return r.voteRepo.ReplaceBallot(previous, ballot)
+}
+
+func (r *RoomLobby) voteChangeAllowed() bool {
+	return r.settings != nil && r.settings.VoteChange().Enabled()
+}
+
+func (r *RoomLobby) isSecret() bool {
+	return r.settings != nil && r.settings.SecretBallot().Enabled()
+}
@@ -0,0 +1,435 @@
+package socketStructs
+
+import (
+	"encoding/json"
+	"log"
+	"suffgo/internal/rooms/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	votedom "suffgo/internal/votes/domain"
+)
+
+// tipos de mensaje que se replican entre instancias
+const (
+	busHello    = domain.BusHello //una instancia nueva pide el estado de la sala
+	busEvent    = "event"         //evento para reenviar a los clientes locales
+	busPresence = "presence"      //clientes que entraron, cambiaron o salieron de otra instancia
+	busState    = "state"         //estado de la votacion
+	busVote     = "vote"          //boleta emitida en otra instancia
+	busVoted    = "voted"         //usuario que emitio una boleta secreta, la boleta no se publica
+	busResults  = "results"       //cada instancia envia los resultados a sus clientes
+	busKick     = "kick"          //expulsar a un usuario conectado a otra instancia
+	BusRoles    = "roles"         //cambiaron los roles o el dueño de la sala
+	busMute     = "mute"          //usuario silenciado o habilitado en el chat
+	BusClose    = "close"         //la sala se cerro, el payload es el motivo
+)
+
+type (
+	busStateData struct {
+		RoomState      string `json:"room_state"`
+		NextProposal   int    `json:"next_proposal"`
+		VotingOpen     bool   `json:"voting_open"`
+		VotingProposal uint   `json:"voting_proposal"`
+		Remaining      int    `json:"remaining"` //segundos de la cuenta regresiva al publicar, se usa al pausar
+		Round          int    `json:"round"`
+		RoundOptions   []uint `json:"round_options,omitempty"`
+	}
+
+	busPresenceData struct {
+		Joined []remoteClient `json:"joined,omitempty"` //alta o actualizacion
+		Left   []uint         `json:"left,omitempty"`
+	}
+
+	// cliente conectado a otra instancia
+	remoteClient struct {
+		Client      ClientData `json:"client"`
+		Whitelisted bool       `json:"whitelisted"`
+	}
+
+	busVoteData struct {
+		ProposalID uint         `json:"proposal_id"`
+		Round      int          `json:"round"`
+		Voter      VoterData    `json:"voter"`
+		Proxy      *VoterData   `json:"proxy,omitempty"`
+		Votes      []busVoteRow `json:"votes"`
+	}
+
+	busVotedData struct {
+		ProposalID uint      `json:"proposal_id"`
+		Round      int       `json:"round"`
+		Voter      VoterData `json:"voter"`
+	}
+
+	busVoteRow struct {
+		ID       uint   `json:"id"`
+		UserID   uint   `json:"user_id"`
+		OptionID uint   `json:"option_id"`
+		Rank     int    `json:"rank"`
+		Weight   int    `json:"weight"`
+		ProxyID  uint   `json:"proxy_id"`
+		Choice   string `json:"choice"`
+		BallotID string `json:"ballot_id"`
+	}
+)
+
+// se suscribe a los mensajes de la sala y pide el estado a las demas instancias
+func (r *RoomLobby) joinBus(bus domain.LobbyBus) {
+	r.bus = bus
+	r.remote = make(map[string]map[uint]remoteClient)
+	if bus == nil {
+		return
+	}
+
+	unsubscribe, err := bus.Subscribe(r.room.ID(), r.handleBusMessage)
+	if err != nil {
+		log.Printf("error subscribing room id = %d to lobby bus: %v \n", r.room.ID().Id, err)
+		return
+	}
+	r.unsubscribe = unsubscribe
+
+	r.publish(busHello, nil)
+}
+
+func (r *RoomLobby) leaveBus() {
+	if r.unsubscribe != nil {
+		r.unsubscribe()
+		r.unsubscribe = nil
+	}
+}
+
+func (r *RoomLobby) publish(kind string, payload interface{}) {
+	if r.bus == nil {
+		return
+	}
+
+	msg := domain.BusMessage{
+		RoomID: r.room.ID().Id,
+		Origin: r.bus.InstanceID(),
+		Kind:   kind,
+	}
+	if payload != nil {
+		msg.Payload = marshalOrPanic(payload)
+	}
+
+	if err := r.bus.Publish(msg); err != nil {
+		log.Printf("error publishing %s on room id = %d: %v \n", kind, r.room.ID().Id, err)
+	}
+}
+
+func (r *RoomLobby) handleBusMessage(msg domain.BusMessage) {
+	if msg.Origin == r.bus.InstanceID() {
+		return
+	}
+
+	var err error
+	switch msg.Kind {
+	case busHello:
+		r.answerHello()
+	case domain.BusResync:
+		r.resync()
+	case busEvent:
+		var event Event
+		if err = json.Unmarshal(msg.Payload, &event); err == nil {
+			r.trackChat(event)
+			r.trackTimer(event)
+			r.broadcastLocal(event)
+		}
+	case busPresence:
+		var presence busPresenceData
+		if err = json.Unmarshal(msg.Payload, &presence); err == nil {
+			r.applyPresence(msg.Origin, presence)
+		}
+	case busState:
+		var state busStateData
+		if err = json.Unmarshal(msg.Payload, &state); err == nil {
+			r.applyState(state)
+		}
+	case busVote:
+		var vote busVoteData
+		if err = json.Unmarshal(msg.Payload, &vote); err == nil {
+			r.applyVote(vote)
+		}
+	case busVoted:
+		var voted busVotedData
+		if err = json.Unmarshal(msg.Payload, &voted); err == nil {
+			r.applyVoted(voted)
+		}
+	case busResults:
+		r.sendResults()
+	case busMute:
+		var muted MutedEvent
+		if err = json.Unmarshal(msg.Payload, &muted); err == nil {
+			r.setMute(muted)
+		}
+	case BusClose:
+		var reason string
+		if err = json.Unmarshal(msg.Payload, &reason); err == nil {
+			r.closeLocal(reason)
+		}
+	case BusRoles:
+		r.ReloadRoles()
+	case busKick:
+		var kick KickUserEvent
+		if err = json.Unmarshal(msg.Payload, &kick); err == nil {
+			r.kickLocal(kick.UserId)
+		}
+	}
+
+	if err != nil {
+		log.Printf("invalid %s message on room id = %d: %v \n", msg.Kind, r.room.ID().Id, err)
+	}
+}
+
+// el bus pudo perder mensajes: se descartan los clientes de otras instancias y se les vuelve a pedir el estado
+func (r *RoomLobby) resync() {
+	r.clientsmx.Lock()
+	r.remote = make(map[string]map[uint]remoteClient)
+	r.broadcastClientList()
+	r.clientsmx.Unlock()
+
+	r.publish(busHello, nil)
+}
+
+// una instancia nueva necesita los clientes, el estado y las boletas de la propuesta actual.
+// Se envia un mensaje por cliente y por boleta para no superar el limite de payload del bus
+func (r *RoomLobby) answerHello() {
+	r.clientsmx.RLock()
+	for client := range r.clients {
+		r.publish(busPresence, busPresenceData{Joined: []remoteClient{r.remoteData(client)}})
+	}
+	r.clientsmx.RUnlock()
+
+	r.publishState()
+
+	for _, muted := range r.activeMutes() {
+		r.publish(busMute, muted)
+	}
+
+	<-r.votesProcesing
+	if r.isSecret() {
+		for _, cast := range r.results {
+			r.publishVoted(cast.voter)
+		}
+	} else {
+		for _, cast := range r.results {
+			r.publishVote(cast)
+		}
+	}
+	r.votesProcesing <- struct{}{}
+}
+
+func (r *RoomLobby) publishState() {
+	r.votingmx.RLock()
+	state := busStateData{
+		RoomState:      r.room.State().CurrentState,
+		NextProposal:   r.nextProposal,
+		VotingOpen:     r.votingOpen,
+		VotingProposal: r.votingProposal,
+		Remaining:      r.remaining,
+		Round:          r.round,
+		RoundOptions:   r.roundOptions,
+	}
+	r.votingmx.RUnlock()
+
+	r.publish(busState, state)
+}
+
+// aplica el estado publicado por la instancia que maneja la votacion. Las replicas no corren el temporizador,
+// reciben los timer_tick y end_voting como eventos
+func (r *RoomLobby) applyState(state busStateData) {
+	r.votingmx.Lock()
+	changed := r.votingProposal != state.VotingProposal || r.round != state.Round
+	r.stopTimerLocked()
+	r.votingOpen = state.VotingOpen
+	r.votingProposal = state.VotingProposal
+	r.nextProposal = state.NextProposal
+	r.remaining = state.Remaining
+	r.round = max(state.Round, 1)
+	r.roundOptions = state.RoundOptions
+	r.votingmx.Unlock()
+
+	r.setLocalState(state.RoomState)
+
+	if !changed {
+		return
+	}
+
+	//nueva propuesta o ronda: se limpian las boletas y los votos de todos los clientes
+	r.clearBallots()
+}
+
+func (r *RoomLobby) publishPresence(client *Client, left bool) {
+	if left {
+		r.publish(busPresence, busPresenceData{Left: []uint{client.User.ID().Id}})
+		return
+	}
+	r.publish(busPresence, busPresenceData{Joined: []remoteClient{r.remoteData(client)}})
+}
+
+func (r *RoomLobby) applyPresence(origin string, presence busPresenceData) {
+	r.clientsmx.Lock()
+	defer r.clientsmx.Unlock()
+
+	if r.remote[origin] == nil {
+		r.remote[origin] = make(map[uint]remoteClient)
+	}
+	for _, joined := range presence.Joined {
+		r.remote[origin][joined.Client.ID] = joined
+	}
+	for _, id := range presence.Left {
+		delete(r.remote[origin], id)
+	}
+	if len(r.remote[origin]) == 0 {
+		delete(r.remote, origin)
+	}
+
+	r.broadcastClientList()
+}
+
+// datos del cliente local que ven las demas instancias, debe llamarse con clientsmx tomado
+func (r *RoomLobby) remoteData(client *Client) remoteClient {
+	return remoteClient{
+		Client: ClientData{
+			ID:       client.User.ID().Id,
+			Name:     client.User.FullName().Name,
+			Lastname: client.User.FullName().Lastname,
+			Username: client.User.Username().Username,
+			Email:    client.User.Email().Email,
+			Voted:    client.voted,
+			Image:    client.User.Image().URL(),
+
+			Connected: client.conn != nil,
+		},
+		Whitelisted: client.whitelisted,
+	}
+}
+
+// clientes conectados a otras instancias, debe llamarse con clientsmx tomado
+func (r *RoomLobby) remoteCount() int {
+	count := 0
+	for _, clients := range r.remote {
+		count += len(clients)
+	}
+	return count
+}
+
+// el usuario tiene una conexion abierta con la sala en otra instancia
+func (r *RoomLobby) ConnectedRemotely(userID uint) bool {
+	r.clientsmx.RLock()
+	defer r.clientsmx.RUnlock()
+
+	for _, clients := range r.remote {
+		if remote, ok := clients[userID]; ok && remote.Client.Connected {
+			return true
+		}
+	}
+	return false
+}
+
+// debe llamarse con votesProcesing tomado. En salas con voto secreto solo se publica quien voto:
+// la boleta no viaja por el bus y cada instancia cuenta las boletas guardadas
+func (r *RoomLobby) publishVote(cast castBallot) {
+	if r.isSecret() {
+		r.publishVoted(cast.voter)
+		return
+	}
+
+	round, _ := r.currentRound()
+	r.publish(busVote, busVoteData{
+		ProposalID: r.currentProposalID(),
+		Round:      round,
+		Voter:      cast.voter,
+		Proxy:      cast.proxy,
+		Votes:      busVoteRows(cast.votes),
+	})
+}
+
+func (r *RoomLobby) publishVoted(voter VoterData) {
+	round, _ := r.currentRound()
+	r.publish(busVoted, busVotedData{ProposalID: r.currentProposalID(), Round: round, Voter: voter})
+}
+
+func busVoteRows(votes []votedom.Vote) []busVoteRow {
+	var rows []busVoteRow
+	for _, v := range votes {
+		rows = append(rows, busVoteRow{
+			ID:       v.ID().Id,
+			UserID:   v.UserID().Id,
+			OptionID: v.OptionID().Id,
+			Rank:     v.Rank(),
+			Weight:   v.Weight(),
+			ProxyID:  v.ProxyID().Id,
+			Choice:   v.Choice(),
+			BallotID: v.BallotID(),
+		})
+	}
+	return rows
+}
+
+func votesFromBus(proposalID uint, round int, rows []busVoteRow) []votedom.Vote {
+	var votes []votedom.Vote
+	for _, row := range rows {
+		id, _ := sv.NewID(row.ID)
+		proposal, _ := sv.NewID(proposalID)
+
+		//las boletas secretas no tienen usuario
+		var userID *sv.ID
+		if row.UserID != 0 {
+			userID, _ = sv.NewID(row.UserID)
+		}
+
+		var v *votedom.Vote
+		if row.Choice == votedom.ChoiceAbstain || row.Choice == votedom.ChoiceBlank {
+			v = votedom.NewNonOptionVote(id, userID, proposal, row.Choice)
+		} else {
+			optionID, _ := sv.NewID(row.OptionID)
+			v = votedom.NewVote(id, userID, optionID)
+		}
+		v.SetRank(row.Rank)
+		v.SetWeight(row.Weight)
+		v.SetBallotID(row.BallotID)
+		v.SetRound(round)
+		if row.ProxyID != 0 {
+			proxyID, _ := sv.NewID(row.ProxyID)
+			v.SetProxyID(proxyID)
+		}
+		votes = append(votes, *v)
+	}
+	return votes
+}
+
+// la boleta es de la propuesta y ronda en curso en esta instancia
+func (r *RoomLobby) currentBallot(proposalID uint, round int) bool {
+	current, _ := r.currentRound()
+	return proposalID == r.currentProposalID() && round == current
+}
+
+func (r *RoomLobby) applyVote(vote busVoteData) {
+	if !r.currentBallot(vote.ProposalID, vote.Round) {
+		return
+	}
+	votes := votesFromBus(vote.ProposalID, vote.Round, vote.Votes)
+
+	<-r.votesProcesing
+	r.results[vote.Voter.ID] = castBallot{voter: vote.Voter, proxy: vote.Proxy, votes: votes}
+	r.votesProcesing <- struct{}{}
+
+	r.clientsmx.RLock()
+	r.broadcastClientList()
+	r.clientsmx.RUnlock()
+}
+
+func (r *RoomLobby) applyVoted(voted busVotedData) {
+	if !r.currentBallot(voted.ProposalID, voted.Round) {
+		return
+	}
+
+	<-r.votesProcesing
+	if _, ok := r.results[voted.Voter.ID]; !ok {
+		r.results[voted.Voter.ID] = castBallot{voter: voted.Voter}
+	}
+	r.votesProcesing <- struct{}{}
+
+	r.clientsmx.RLock()
+	r.broadcastClientList()
+	r.clientsmx.RUnlock()
+}
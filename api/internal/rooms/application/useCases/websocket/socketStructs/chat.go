@@ -0,0 +1,269 @@
+package socketStructs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+const (
+	ChatMaxLength   = 1000             //caracteres por mensaje
+	ChatHistorySize = 50               //mensajes que se reenvian al entrar a la sala
+	ChatRateLimit   = 5                //mensajes por usuario dentro de ChatRateWindow
+	ChatRateWindow  = 10 * time.Second //ventana del limite de mensajes
+)
+
+// el remitente es el usuario de la conexion, no lo que envia el cliente
+func SendMessage(event Event, c *Client) error {
+	var newMessage NewMessageEvent
+	if err := json.Unmarshal(event.Payload, &newMessage); err != nil {
+		return newProtocolError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	text := strings.TrimSpace(newMessage.Message)
+	if text == "" || utf8.RuneCountInString(text) > ChatMaxLength {
+		return newProtocolError(ErrCodeInvalidMessage, fmt.Sprintf("message must have between 1 and %d characters", ChatMaxLength))
+	}
+
+	if err := c.lobby.allowChat(c.User.ID().Id); err != nil {
+		return err
+	}
+
+	saved, err := c.lobby.roomRepo.SaveChatMessage(domain.ChatMessage{
+		RoomID:   c.lobby.room.ID().Id,
+		UserID:   c.User.ID().Id,
+		Username: c.User.Username().Username,
+		Message:  text,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		return newProtocolError(ErrCodeInternal, "error saving message")
+	}
+
+	message := Event{
+		Action:  EventSendMessage,
+		Payload: marshalOrPanic(chatEvent(*saved)),
+	}
+	c.lobby.trackChat(message)
+
+	//en v1 el emisor ya muestra su mensaje, en v2 recibe el mensaje con id y hora del servidor
+	for client := range c.Lobby().Clients() {
+		if client.conn != nil && (client != c || c.version >= ProtocolV2) {
+			client.egress <- message
+		}
+	}
+	c.lobby.stream(message)
+	c.lobby.publish(busEvent, message)
+	return nil
+}
+
+func MuteUser(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	var muteEvent MuteUserEvent
+	if err := json.Unmarshal(event.Payload, &muteEvent); err != nil || muteEvent.Duration < 0 {
+		return newProtocolError(ErrCodeInvalidPayload, "invalid mute")
+	}
+
+	if (v.Role{Role: c.lobby.role(muteEvent.UserId)}).CanModerate() {
+		return newProtocolError(ErrCodeForbidden, "moderators cannot be muted")
+	}
+
+	muted := MutedEvent{UserId: muteEvent.UserId, Muted: true}
+	if muteEvent.Duration > 0 {
+		until := time.Now().Add(time.Duration(muteEvent.Duration) * time.Second)
+		muted.Until = &until
+	}
+
+	c.lobby.setMute(muted)
+	c.lobby.publish(busMute, muted)
+	c.lobby.broadcast(Event{
+		Action:  EventMuteUser,
+		Payload: marshalOrPanic(muted),
+	})
+	return nil
+}
+
+func UnmuteUser(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	var muteEvent MuteUserEvent
+	if err := json.Unmarshal(event.Payload, &muteEvent); err != nil {
+		return newProtocolError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	muted := MutedEvent{UserId: muteEvent.UserId, Muted: false}
+	c.lobby.setMute(muted)
+	c.lobby.publish(busMute, muted)
+	c.lobby.broadcast(Event{
+		Action:  EventUnmuteUser,
+		Payload: marshalOrPanic(muted),
+	})
+	return nil
+}
+
+func DeleteMessage(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	var deleteEvent DeleteMessageEvent
+	if err := json.Unmarshal(event.Payload, &deleteEvent); err != nil {
+		return newProtocolError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	messageId, err := sv.NewID(deleteEvent.MessageId)
+	if err != nil {
+		return newProtocolError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	err = c.lobby.roomRepo.DeleteChatMessage(c.lobby.room.ID(), *messageId, c.User.ID())
+	if errors.Is(err, roomerr.ErrMessageNotFound) {
+		return newProtocolError(ErrCodeMessageNotFound, err.Error())
+	} else if err != nil {
+		log.Println(err.Error())
+		return newProtocolError(ErrCodeInternal, "error deleting message")
+	}
+
+	deleted := Event{
+		Action:  EventDeleteMessage,
+		Payload: marshalOrPanic(deleteEvent),
+	}
+	c.lobby.trackChat(deleted)
+	c.lobby.broadcast(deleted)
+	return nil
+}
+
+func chatEvent(message domain.ChatMessage) SendMessageEvent {
+	return SendMessageEvent{
+		ID:      message.ID,
+		Message: message.Message,
+		From:    message.Username,
+		UserID:  message.UserID,
+		SentAt:  message.SentAt,
+	}
+}
+
+// ultimos mensajes de la sala para los usuarios que entran despues
+func (r *RoomLobby) loadChat() {
+	messages, err := r.roomRepo.GetChatMessages(r.room.ID(), ChatHistorySize)
+	if err != nil {
+		log.Printf("error loading chat of room id = %d: %v \n", r.room.ID().Id, err)
+	}
+
+	r.chatmx.Lock()
+	defer r.chatmx.Unlock()
+	r.chat = nil
+	for _, message := range messages {
+		r.chat = append(r.chat, chatEvent(message))
+	}
+	r.muted = make(map[uint]*time.Time)
+	r.chatSent = make(map[uint][]time.Time)
+}
+
+// actualiza el historial con los mensajes nuevos y borrados, tambien los de otras instancias
+func (r *RoomLobby) trackChat(event Event) {
+	r.chatmx.Lock()
+	defer r.chatmx.Unlock()
+
+	switch event.Action {
+	case EventSendMessage:
+		var message SendMessageEvent
+		if err := json.Unmarshal(event.Payload, &message); err != nil {
+			return
+		}
+		r.chat = append(r.chat, message)
+		if len(r.chat) > ChatHistorySize {
+			r.chat = r.chat[len(r.chat)-ChatHistorySize:]
+		}
+	case EventDeleteMessage:
+		var deleted DeleteMessageEvent
+		if err := json.Unmarshal(event.Payload, &deleted); err != nil {
+			return
+		}
+		for i, message := range r.chat {
+			if message.ID == deleted.MessageId {
+				r.chat = append(r.chat[:i], r.chat[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (r *RoomLobby) sendChatHistory(client *Client) {
+	r.chatmx.Lock()
+	history := ChatHistoryEvent{Messages: append([]SendMessageEvent(nil), r.chat...)}
+	r.chatmx.Unlock()
+
+	client.egress <- Event{
+		Action:  EventChatHistory,
+		Payload: marshalOrPanic(history),
+	}
+}
+
+// rechaza el mensaje si el usuario esta silenciado o supero el limite de mensajes
+func (r *RoomLobby) allowChat(userID uint) error {
+	r.chatmx.Lock()
+	defer r.chatmx.Unlock()
+
+	now := time.Now()
+	if until, muted := r.muted[userID]; muted {
+		if until == nil {
+			return newProtocolError(ErrCodeMuted, "you are muted")
+		}
+		if now.Before(*until) {
+			return newProtocolError(ErrCodeMuted, fmt.Sprintf("you are muted for %d seconds", int(until.Sub(now).Seconds())+1))
+		}
+		delete(r.muted, userID)
+	}
+
+	//ventana deslizante: se descartan los envios viejos
+	sent := r.chatSent[userID]
+	for len(sent) > 0 && now.Sub(sent[0]) >= ChatRateWindow {
+		sent = sent[1:]
+	}
+	if len(sent) >= ChatRateLimit {
+		r.chatSent[userID] = sent
+		return newProtocolError(ErrCodeRateLimited, fmt.Sprintf("at most %d messages every %d seconds", ChatRateLimit, int(ChatRateWindow.Seconds())))
+	}
+	r.chatSent[userID] = append(sent, now)
+	return nil
+}
+
+func (r *RoomLobby) setMute(muted MutedEvent) {
+	r.chatmx.Lock()
+	defer r.chatmx.Unlock()
+
+	if muted.Muted {
+		r.muted[muted.UserId] = muted.Until
+	} else {
+		delete(r.muted, muted.UserId)
+	}
+}
+
+// silencios vigentes, se envian a las instancias nuevas
+func (r *RoomLobby) activeMutes() []MutedEvent {
+	r.chatmx.Lock()
+	defer r.chatmx.Unlock()
+
+	var mutes []MutedEvent
+	for userID, until := range r.muted {
+		if until == nil || time.Now().Before(*until) {
+			mutes = append(mutes, MutedEvent{UserId: userID, Muted: true, Until: until})
+		}
+	}
+	return mutes
+}
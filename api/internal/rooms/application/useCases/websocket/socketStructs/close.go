@@ -0,0 +1,47 @@
+package socketStructs
+
+import "log"
+
+// cierra la sala y desconecta a los clientes de esta instancia
+func (r *RoomLobby) Close(reason string) {
+	r.ChangeRoomState(r.room.State().ClosedState(), nil, reason)
+	r.closeLocal(reason)
+}
+
+// desconecta a todos los clientes y consumidores de esta instancia y descarta la sala
+func (r *RoomLobby) closeLocal(reason string) {
+	r.votingmx.Lock()
+	r.votingOpen = false
+	r.stopTimerLocked()
+	r.votingmx.Unlock()
+
+	//en las replicas evita que removeClient reinicie la sala
+	if r.room.State().Live() {
+		r.setLocalState(r.room.State().ClosedState())
+	}
+	r.broadcastLocal(Event{
+		Action:  EventRoomClosed,
+		Payload: marshalOrPanic(RoomClosedEvent{Reason: reason}),
+	})
+
+	r.clientsmx.RLock()
+	clients := make([]*Client, 0, len(r.clients)+len(r.waiting))
+	for client := range r.clients {
+		clients = append(clients, client)
+	}
+	clients = append(clients, r.waiting...)
+	r.clientsmx.RUnlock()
+
+	for _, client := range clients {
+		r.removeClient(client)
+	}
+
+	r.leaveBus()
+	r.closeStreams()
+	//removeClient puede haber avisado ya que la sala quedo vacia
+	select {
+	case r.Empty <- struct{}{}:
+	default:
+	}
+	log.Printf("room id = %d closed: %s \n", r.room.ID().Id, reason)
+}
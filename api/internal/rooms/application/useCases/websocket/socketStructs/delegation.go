@@ -0,0 +1,137 @@
+package socketStructs
+
+import (
+	"log"
+	dldom "suffgo/internal/delegations/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	userdom "suffgo/internal/users/domain"
+	votedom "suffgo/internal/votes/domain"
+)
+
+// boleta emitida en la propuesta actual, por el propio usuario o por su delegado
+type castBallot struct {
+	voter VoterData
+	proxy *VoterData
+	votes []votedom.Vote
+}
+
+// boletas a contar en la ronda actual. En salas con voto secreto se leen de la base: las boletas no
+// viajan por el bus y las de results pueden no tener votos si el usuario voto en otra instancia o antes de un reinicio
+func (r *RoomLobby) castBallots() ([]castBallot, error) {
+	if r.isSecret() {
+		return r.storedSecretBallots()
+	}
+
+	<-r.votesProcesing
+	defer func() {
+		r.votesProcesing <- struct{}{}
+	}()
+
+	ballots := make([]castBallot, 0, len(r.results))
+	for _, cast := range r.results {
+		ballots = append(ballots, cast)
+	}
+	return ballots, nil
+}
+
+// boletas secretas guardadas de la ronda actual, agrupadas por id de boleta y sin votante
+func (r *RoomLobby) storedSecretBallots() ([]castBallot, error) {
+	proposal := r.currentProposal()
+	if proposal == nil {
+		return nil, nil
+	}
+
+	round, _ := r.currentRound()
+	votes, err := r.voteRepo.GetByProposal(proposal.ID(), r.session, round)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	var ballots []castBallot
+	for _, vote := range votes {
+		i, ok := index[vote.BallotID()]
+		if !ok {
+			i = len(ballots)
+			index[vote.BallotID()] = i
+			ballots = append(ballots, castBallot{})
+		}
+		ballots[i].votes = append(ballots[i].votes, vote)
+	}
+	return ballots, nil
+}
+
+// carga los votos por poder de la sala. Las delegaciones no cambian una vez que la sala esta online
+func (r *RoomLobby) loadDelegations(delegationRepo dldom.DelegationRepository, userRepo userdom.UserRepository) {
+	r.represented = make(map[uint][]VoterData)
+	r.delegatedTo = make(map[uint]uint)
+	r.delegatedWeight = make(map[uint]int)
+
+	if delegationRepo == nil {
+		return
+	}
+
+	delegations, err := delegationRepo.GetByRoom(r.room.ID())
+	if err != nil {
+		log.Printf("error loading delegations of room id = %d: %v \n", r.room.ID().Id, err)
+		return
+	}
+
+	for _, delegation := range delegations {
+		delegator, err := userRepo.GetByID(delegation.DelegatorID())
+		if err != nil || delegator == nil {
+			log.Printf("delegator id = %d not found \n", delegation.DelegatorID().Id)
+			continue
+		}
+
+		weight, err := r.roomRepo.WhitelistWeight(r.room.ID(), delegation.DelegatorID())
+		if err != nil {
+			weight = 1
+		}
+
+		delegate := delegation.DelegateID().Id
+		r.represented[delegate] = append(r.represented[delegate], VoterData{
+			Username: delegator.Username().Username,
+			ID:       delegator.ID().Id,
+		})
+		r.delegatedTo[delegator.ID().Id] = delegate
+		r.delegatedWeight[delegate] += weight
+	}
+}
+
+// usuario en cuyo nombre se vota. Devuelve nil si el cliente no puede votar por el
+func (r *RoomLobby) voterFor(c *Client, onBehalfOf uint) (*VoterData, *VoterData) {
+	self := VoterData{Username: c.User.Username().Username, ID: c.User.ID().Id}
+
+	if onBehalfOf == 0 || onBehalfOf == self.ID {
+		return &self, nil
+	}
+
+	for _, represented := range r.represented[self.ID] {
+		if represented.ID == onBehalfOf {
+			voter := represented
+			return &voter, &self
+		}
+	}
+
+	return nil, nil
+}
+
+// datos de representacion que se muestran en update_client_list
+func (r *RoomLobby) representedData(userID uint) ([]RepresentedData, int) {
+	var represented []RepresentedData
+	for _, voter := range r.represented[userID] {
+		_, voted := r.results[voter.ID]
+		represented = append(represented, RepresentedData{
+			ID:       voter.ID,
+			Username: voter.Username,
+			Voted:    voted,
+		})
+	}
+	return represented, r.delegatedWeight[userID]
+}
+
+func (r *RoomLobby) hasDelegated(userID sv.ID) bool {
+	_, ok := r.delegatedTo[userID.Id]
+	return ok
+}
@@ -0,0 +1,106 @@
+package socketStructs
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"suffgo/internal/rooms/domain"
+)
+
+// tiempos del heartbeat de cada conexion
+type Heartbeat struct {
+	PingInterval time.Duration //debe ser menor que PongWait
+	PongWait     time.Duration
+	WriteWait    time.Duration
+}
+
+var DefaultHeartbeat = Heartbeat{
+	PingInterval: 25 * time.Second,
+	PongWait:     60 * time.Second,
+	WriteWait:    10 * time.Second,
+}
+
+// estado de la conexion actual del cliente, se reinicia al reconectarse
+type connHealth struct {
+	mx       sync.Mutex
+	pingSent time.Time
+	lastPong time.Time
+	latency  time.Duration
+}
+
+func (h *connHealth) pinged() {
+	h.mx.Lock()
+	h.pingSent = time.Now()
+	h.mx.Unlock()
+}
+
+func (h *connHealth) ponged() {
+	h.mx.Lock()
+	h.lastPong = time.Now()
+	if !h.pingSent.IsZero() {
+		h.latency = h.lastPong.Sub(h.pingSent)
+	}
+	h.mx.Unlock()
+}
+
+func (h *connHealth) reset() {
+	h.mx.Lock()
+	h.pingSent = time.Time{}
+	h.lastPong = time.Time{}
+	h.latency = 0
+	h.mx.Unlock()
+}
+
+// el cliente no respondio el ping a tiempo
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// salud de las conexiones de la sala en esta instancia
+func (r *RoomLobby) Health() domain.LobbyHealth {
+	health := domain.LobbyHealth{
+		RoomID: r.room.ID().Id,
+		Reaped: r.reaped.Load(),
+	}
+
+	r.clientsmx.RLock()
+	defer r.clientsmx.RUnlock()
+
+	var totalLatency time.Duration
+	measured := 0
+	for client := range r.clients {
+		client.health.mx.Lock()
+		clientHealth := domain.ClientHealth{
+			UserID:    client.User.ID().Id,
+			Connected: client.conn != nil,
+			LatencyMs: client.health.latency.Milliseconds(),
+		}
+		if !client.health.lastPong.IsZero() {
+			lastPong := client.health.lastPong
+			clientHealth.LastPong = &lastPong
+		}
+		if client.health.latency > 0 {
+			totalLatency += client.health.latency
+			measured++
+		}
+		client.health.mx.Unlock()
+
+		if clientHealth.Connected {
+			health.Connected++
+		} else {
+			health.Reconnecting++
+		}
+		health.Clients = append(health.Clients, clientHealth)
+	}
+
+	health.Queued = len(r.waiting)
+	health.Remote = r.remoteCount()
+	if measured > 0 {
+		health.AvgLatencyMs = (totalLatency / time.Duration(measured)).Milliseconds()
+	}
+
+	return health
+}
@@ -4,53 +4,125 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
-	opterr "suffgo/internal/options/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
 	votedom "suffgo/internal/votes/domain"
 )
 
-// Si el id es = 0, no voto nada
+// Si el id es = 0 en boletas simples se registra un voto en blanco
 func ReceiveVote(event Event, c *Client) error {
 
+	<-c.lobby.votesProcesing
 	defer func() {
 		c.lobby.votesProcesing <- struct{}{}
 	}()
 
-	//si ya voto no podes volver a hacerlo
-	if c.voted {
-		return nil
+	if c.lobby.isPaused() {
+		return newProtocolError(ErrCodeVotingPaused, "voting is paused")
+	}
+
+	//fuera de tiempo o sin propuesta abierta no se aceptan votos
+	if !c.lobby.isVotingOpen() {
+		return newProtocolError(ErrCodeVotingClosed, "voting is closed")
+	}
+
+	//los observadores solo miran la asamblea
+	if c.lobby.IsObserver(c.User.ID().Id) {
+		return newProtocolError(ErrCodeObserver, "observers cannot vote")
 	}
 
 	var voteEvent VoteEvent
 
 	if err := json.Unmarshal(event.Payload, &voteEvent); err != nil {
-		return err
+		return newProtocolError(ErrCodeInvalidPayload, err.Error())
+	}
+
+	//el delegado puede votar en nombre de los usuarios que representa
+	voter, proxy := c.lobby.voterFor(c, voteEvent.OnBehalfOf)
+	if voter == nil {
+		return newProtocolError(ErrCodeNotRepresented, "you do not represent this user")
 	}
 
-	votedOpt, err := sv.NewID(uint(voteEvent.OptionId))
+	userId, err := sv.NewID(voter.ID)
 	if err != nil {
 		log.Println(err.Error())
-		return nil
+		return newProtocolError(ErrCodeInternal, "invalid voter")
 	}
-	//chequeo que la opcion exista
-	_, err = c.lobby.optRepo.GetByID(*votedOpt)
-	if errors.Is(err, opterr.ErrOptNotFound) {
-		log.Printf("user %s voted in blank \n", c.User.Username().Username)
+
+	if proxy == nil && c.lobby.hasDelegated(*userId) {
+		return newProtocolError(ErrCodeVoteDelegated, "your vote was delegated")
 	}
 
-	userId := c.User.ID()
-	vote := votedom.NewVote(nil, &userId, votedOpt)
-	<-c.lobby.votesProcesing
-	vote, err = c.lobby.voteRepo.Save(*vote)
+	//si ya voto solo puede cambiar la boleta si la sala lo permite
+	previous, voted := c.lobby.results[voter.ID]
+	if (voted || (proxy == nil && c.voted)) && !c.lobby.voteChangeAllowed() {
+		return newProtocolError(ErrCodeInvalidBallot, "vote already cast")
+	}
+
+	//una boleta secreta solo se puede cambiar en la instancia donde se emitio y antes de un reinicio:
+	//fuera de ahi no se sabe cual es la del usuario
+	if voted && len(previous.votes) == 0 {
+		return newProtocolError(ErrCodeInvalidBallot, "your ballot can no longer be changed")
+	}
 
+	proposal := c.lobby.currentProposal()
+	if proposal == nil {
+		return newProtocolError(ErrCodeNoProposal, "there is no proposal to vote")
+	}
+
+	//en boletas simples la opcion 0 se toma como voto en blanco
+	choice := voteEvent.Choice
+	if choice == "" && !proposal.BallotType().IsRanked() && !proposal.BallotType().IsMultiSelect() && voteEvent.OptionId == 0 {
+		choice = votedom.ChoiceBlank
+	}
+
+	var ballot []votedom.Vote
+
+	switch {
+	case choice == votedom.ChoiceAbstain || choice == votedom.ChoiceBlank:
+		proposalId := proposal.ID()
+		ballot = []votedom.Vote{*votedom.NewNonOptionVote(nil, userId, &proposalId, choice)}
+	case choice != "" && choice != votedom.ChoiceOption:
+		err = errors.New("invalid choice")
+	case proposal.BallotType().IsRanked():
+		//propuestas ranked, approval y multi: la boleta tiene una fila por opcion elegida
+		ballot, err = c.lobby.rankedBallot(proposal.ID(), *userId, voteEvent.Ranking)
+	case proposal.BallotType().IsMultiSelect():
+		ballot, err = c.lobby.selectionBallot(proposal, *userId, voteEvent.Options)
+	default:
+		ballot, err = c.lobby.buildBallot(proposal.ID(), *userId, []uint{voteEvent.OptionId})
+	}
 	if err != nil {
-		log.Println(err.Error()) 
-		return nil
+		return newProtocolError(ErrCodeInvalidBallot, err.Error())
 	}
-	c.lobby.results[c] = *vote
 
-	c.voted = true
+	//en salas con voto secreto no se registra quien emitio el voto
+	if proxy != nil && !c.lobby.isSecret() {
+		proxyId, _ := sv.NewID(proxy.ID)
+		for i := range ballot {
+			ballot[i].SetProxyID(proxyId)
+		}
+	}
+
+	var saved []votedom.Vote
+	if voted {
+		saved, err = c.lobby.changeBallot(previous.votes, ballot)
+	} else {
+		saved, err = c.lobby.saveBallot(proposal, *userId, ballot)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return newProtocolError(ErrCodeInternal, "error saving vote")
+	}
+	c.lobby.results[voter.ID] = castBallot{voter: *voter, proxy: proxy, votes: saved}
+	c.lobby.publishVote(c.lobby.results[voter.ID])
+
+	c.lobby.clientsmx.Lock()
+	if proxy == nil {
+		c.voted = true
+		c.lobby.publishPresence(c, false)
+	}
 	c.lobby.broadcastClientList() //con esto informo el momento en que un usuario vota
+	c.lobby.clientsmx.Unlock()
 
 	return nil
 }
@@ -58,41 +130,45 @@ func ReceiveVote(event Event, c *Client) error {
 func KickUser(event Event, c *Client) error {
 	var kickEvent *KickUserEvent
 
-	if err := json.Unmarshal(event.Payload, &kickEvent); err != nil {
-		errorEvent := Event{
-			Action:  EventError,
-			Payload: marshalOrPanic(ErrorEvent{Message: "unmarshalling error"}),
-		}
-
-		c.egress <- errorEvent
+	if err := json.Unmarshal(event.Payload, &kickEvent); err != nil || kickEvent == nil {
+		return newProtocolError(ErrCodeInvalidPayload, "unmarshalling error")
+	}
 
-		return nil
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
 	}
 
-	if c.lobby.admin.User.ID().Id != c.User.ID().Id {
-		errorEvent := Event{
-			Action:  EventError,
-			Payload: marshalOrPanic(ErrorEvent{Message: "lack of privileges"}),
-		}
+	//el usuario puede estar conectado a otra instancia
+	c.lobby.publish(busKick, kickEvent)
 
-		c.egress <- errorEvent
-		return nil
+	if c.lobby.kickLocal(kickEvent.UserId) {
+		log.Printf("User with id = %d deleted \n", kickEvent.UserId)
+	} else {
+		log.Println("User to kick not found")
 	}
 
+	return nil
+}
+
+// expulsa al usuario si esta conectado a esta instancia y avisa al resto de los clientes locales
+func (r *RoomLobby) kickLocal(userID uint) bool {
 	clientKicked := false
-	for client := range c.lobby.clients {
-		if client.User.ID().Id == kickEvent.UserId {
+	for client := range r.Clients() {
+		if client.User.ID().Id == userID {
 			errorEvent := Event{
 				Action:  EventKickUser,
 				Payload: marshalOrPanic(ErrorEvent{Message: "you were kicked out of the room"}),
 			}
 
-			client.egress <- errorEvent
-			<-client.errorSent
-			c.lobby.removeClient(client)
+			//si esta esperando reconexion se lo saca sin avisarle
+			if client.Conn() != nil {
+				client.egress <- errorEvent
+				<-client.errorSent
+			}
+			r.removeClient(client)
 			clientKicked = true
 
-		} else {
+		} else if client.Conn() != nil {
 			errorEvent := Event{
 				Action:  EventKickInfoUser,
 				Payload: marshalOrPanic(ErrorEvent{Message: "an user was kicked"}),
@@ -102,11 +178,5 @@ func KickUser(event Event, c *Client) error {
 		}
 	}
 
-	if clientKicked {
-		log.Printf("User with id = %d deleted \n", kickEvent.UserId)
-	} else {
-		log.Println("User to kick not found")
-	}
-
-	return nil
+	return clientKicked
 }
@@ -0,0 +1,151 @@
+package socketStructs
+
+import (
+	"log"
+	"time"
+
+	"suffgo/internal/rooms/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+// persiste el progreso de la sala y lo replica en las demas instancias
+func (r *RoomLobby) saveState() {
+	r.persistState()
+	r.publishState()
+}
+
+func (r *RoomLobby) persistState() {
+	r.votingmx.RLock()
+	state := domain.LobbyState{
+		NextProposal:   r.nextProposal,
+		VotingOpen:     r.votingOpen,
+		VotingProposal: r.votingProposal,
+		Round:          r.round,
+	}
+	if r.votingOpen && r.timer != nil {
+		closesAt := time.Now().Add(time.Duration(r.timer.remaining) * time.Second)
+		state.ClosesAt = &closesAt
+	}
+	if r.isPaused() {
+		state.Remaining = r.remaining
+	}
+	r.votingmx.RUnlock()
+
+	if err := r.roomRepo.SaveLobbyState(r.room.ID(), state); err != nil {
+		log.Printf("error saving state of room id = %d: %v \n", r.room.ID().Id, err)
+	}
+}
+
+// recupera el progreso guardado de la sala despues de un reinicio del servidor, antes de que se conecten clientes.
+// No se publica: si la sala sigue viva en otra instancia, su respuesta al hello tiene prioridad
+func (r *RoomLobby) Restore(state domain.LobbyState) {
+	r.votingmx.Lock()
+	r.nextProposal = state.NextProposal
+	r.votingProposal = state.VotingProposal
+	r.votingmx.Unlock()
+
+	if err := r.restoreRound(state.Round); err != nil {
+		log.Printf("error restoring round of room id = %d: %v \n", r.room.ID().Id, err)
+	}
+
+	if err := r.restoreBallots(); err != nil {
+		log.Printf("error restoring ballots of room id = %d: %v \n", r.room.ID().Id, err)
+	}
+
+	if state.VotingOpen && r.isPaused() {
+		//pausada: la cuenta regresiva sigue al reanudar la sala
+		r.votingmx.Lock()
+		r.votingOpen = true
+		r.remaining = state.Remaining
+		r.votingmx.Unlock()
+	} else if state.VotingOpen {
+		//la cuenta regresiva sigue desde donde quedo, si ya vencio la votacion queda cerrada
+		duration := 0
+		if state.ClosesAt != nil {
+			duration = int(time.Until(*state.ClosesAt).Seconds())
+		}
+		if state.ClosesAt == nil || duration > 0 {
+			r.openVotingFor(state.VotingProposal, duration)
+		} else {
+			r.persistState()
+		}
+	}
+
+	log.Printf("room id = %d restored at proposal %d \n", r.room.ID().Id, state.NextProposal)
+}
+
+// recupera la ronda de la propuesta actual y sus opciones
+func (r *RoomLobby) restoreRound(number int) error {
+	proposal := r.currentProposal()
+	if proposal == nil || number <= 1 {
+		return nil
+	}
+
+	rounds, err := r.propRepo.GetRounds(proposal.ID(), r.session)
+	if err != nil {
+		return err
+	}
+
+	r.votingmx.Lock()
+	defer r.votingmx.Unlock()
+	for _, round := range rounds {
+		if round.Number == number {
+			r.round = number
+			r.roundOptions = round.Options
+		}
+	}
+	return nil
+}
+
+// arma las boletas de la ronda actual a partir de los votos guardados
+func (r *RoomLobby) restoreBallots() error {
+	proposal := r.currentProposal()
+	if proposal == nil {
+		return nil
+	}
+
+	round, _ := r.currentRound()
+	votes, err := r.voteRepo.GetByProposal(proposal.ID(), r.session, round)
+	if err != nil {
+		return err
+	}
+
+	results := make(map[uint]castBallot)
+	if r.isSecret() {
+		//de las boletas secretas solo se recupera quienes votaron, los totales se cuentan de los votos guardados
+		participants, err := r.voteRepo.ParticipantsByProposal(proposal.ID(), r.session, round)
+		if err != nil {
+			return err
+		}
+		for _, userID := range participants {
+			results[userID.Id] = castBallot{voter: r.voterData(userID)}
+		}
+	} else {
+		for _, vote := range votes {
+			cast, ok := results[vote.UserID().Id]
+			if !ok {
+				cast.voter = r.voterData(vote.UserID())
+				if vote.ProxyID().Id != 0 {
+					proxy := r.voterData(vote.ProxyID())
+					cast.proxy = &proxy
+				}
+			}
+			cast.votes = append(cast.votes, vote)
+			results[vote.UserID().Id] = cast
+		}
+	}
+
+	<-r.votesProcesing
+	r.results = results
+	r.votesProcesing <- struct{}{}
+
+	return nil
+}
+
+func (r *RoomLobby) voterData(userID sv.ID) VoterData {
+	voter := VoterData{ID: userID.Id}
+	if user, err := r.userRepo.GetByID(userID); err == nil && user != nil {
+		voter.Username = user.Username().Username
+	}
+	return voter
+}
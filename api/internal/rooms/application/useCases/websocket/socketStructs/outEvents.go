@@ -1,86 +1,84 @@
 package socketStructs
 
 import (
+	"fmt"
 	"log"
 	optdom "suffgo/internal/options/domain"
+	propdom "suffgo/internal/proposals/domain"
+	propv "suffgo/internal/proposals/domain/valueObjects"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	votedom "suffgo/internal/votes/domain"
 )
 
 func StartVoting(event Event, c *Client) error {
 	log.Printf("room with id = %d has begun \n", c.Lobby().room.AdminID().Id)
 
-	if c.User.ID().Id != c.Lobby().Admin().User.ID().Id {
+	if !c.lobby.canModerate(c) {
 
-		errorEvent := Event{
-			Action:  EventError,
-			Payload: marshalOrPanic(ErrorEvent{Message: "You are not the admin"}),
-		}
+		return newProtocolError(ErrCodeForbidden, "You are not the admin")
+	}
 
-		c.egress <- errorEvent
-		return nil
+	if c.lobby.isPaused() {
+		return newProtocolError(ErrCodeVotingPaused, "resume the voting first")
 	}
 
-	//esto deberia ser chequeado antes, no deberia poder comenzar una sala que no tiene propuestas
-	if len(c.Lobby().proposals) >= c.lobby.nextProposal {
+	c.lobby.clientsmx.RLock()
+	quorum := c.lobby.quorumStatus()
+	c.lobby.clientsmx.RUnlock()
 
-		lastProp := false
-		if c.lobby.nextProposal == len(c.Lobby().proposals)-1 {
-			lastProp = true
-		}
+	if !quorum.Reached {
+		return newProtocolError(ErrCodeQuorumNotReached, fmt.Sprintf("quorum not reached: %d of %d users connected", quorum.Present, quorum.Required))
+	}
 
-		proposal := c.Lobby().proposals[c.lobby.nextProposal]
-		options, err := c.lobby.optRepo.GetByProposal(proposal.ID())
-		if err != nil {
-			errorEvent := Event{
-				Action:  EventError,
-				Payload: marshalOrPanic(ErrorEvent{Message: "error fetching options"}),
-			}
+	//solo se empieza desde online, una sala en curso avanza con next_proposal
+	if c.lobby.room.State().CurrentState != v.StateOnline {
+		return newProtocolError(ErrCodeInvalidState, "voting can only be started in an online room")
+	}
 
-			c.egress <- errorEvent
+	if c.lobby.nextProposal >= len(c.Lobby().proposals) {
+		return newProtocolError(ErrCodeNoProposal, "the room has no proposals to vote")
+	}
 
-			return nil
-		}
+	lastProp := false
+	if c.lobby.nextProposal == len(c.Lobby().proposals)-1 {
+		lastProp = true
+	}
 
-		var optionsValue []optdom.OptionDTO
-		for _, option := range options {
+	proposal := c.Lobby().proposals[c.lobby.nextProposal]
+	options, err := c.lobby.optRepo.GetByProposal(proposal.ID())
+	if err != nil {
+		return newProtocolError(ErrCodeInternal, "error fetching options")
+	}
 
-			opt := optdom.OptionDTO{
-				ID:         option.ID().Id,
-				Value:      option.Value().Value,
-				ProposalID: option.ProposalID().Id,
-			}
-			optionsValue = append(optionsValue, opt)
-		}
+	var optionsValue []optdom.OptionDTO
+	for _, option := range options {
 
-		proposalevt := ProposalEvent{
-			ID:          proposal.ID().Id,
-			Archive:     &proposal.Archive().Archive,
-			Description: &proposal.Description().Description,
-			Title:       proposal.Title().Title,
-			Options:     optionsValue,
-			LastProp:    lastProp,
-		}
-
-		prop := Event{
-			Action:  EventFirstProp,
-			Payload: marshalOrPanic(proposalevt),
+		opt := optdom.OptionDTO{
+			ID:         option.ID().Id,
+			Value:      option.Value().Value,
+			ProposalID: option.ProposalID().Id,
 		}
+		optionsValue = append(optionsValue, opt)
+	}
 
-		for client := range c.Lobby().Clients() {
-			if client.conn != nil { 
-				client.egress <- prop
-			}
-		}
+	//la transicion se valida antes de abrir la votacion
+	if err := c.lobby.ChangeRoomState(v.StateInProgress, c, "voting started"); err != nil {
+		return newProtocolError(ErrCodeInvalidState, "the room cannot start voting")
 	}
 
-	c.lobby.room.State().SetState("in progress")
-	_, err := c.lobby.roomRepo.Update(c.lobby.room)
+	proposalevt := c.lobby.proposalEvent(proposal, optionsValue, lastProp)
 
-	if err != nil {
-		return nil
+	prop := Event{
+		Action:  EventFirstProp,
+		Payload: marshalOrPanic(proposalevt),
 	}
 
+	c.lobby.broadcast(prop)
+	c.lobby.openVoting(proposal.ID().Id)
+
 	log.Println(c.lobby.room.State().CurrentState)
 	c.lobby.nextProposal++
+	c.lobby.saveState()
 
 	return nil
 }
@@ -88,15 +86,13 @@ func StartVoting(event Event, c *Client) error {
 func NextProposal(event Event, c *Client) error {
 
 	log.Println("enviando next proposal")
-	if c.User.ID().Id != c.Lobby().Admin().User.ID().Id {
+	if !c.lobby.canModerate(c) {
 
-		errorEvent := Event{
-			Action:  EventError,
-			Payload: marshalOrPanic(ErrorEvent{Message: "You are not the admin"}),
-		}
+		return newProtocolError(ErrCodeForbidden, "You are not the admin")
+	}
 
-		c.egress <- errorEvent
-		return nil
+	if c.lobby.isPaused() {
+		return newProtocolError(ErrCodeVotingPaused, "resume the voting first")
 	}
 
 	lastProp := false
@@ -108,14 +104,7 @@ func NextProposal(event Event, c *Client) error {
 		proposal := c.Lobby().proposals[c.lobby.nextProposal]
 		options, err := c.lobby.optRepo.GetByProposal(proposal.ID())
 		if err != nil {
-			errorEvent := Event{
-				Action:  EventError,
-				Payload: marshalOrPanic(ErrorEvent{Message: "error fetching options"}),
-			}
-
-			c.egress <- errorEvent
-
-			return nil
+			return newProtocolError(ErrCodeInternal, "error fetching options")
 		}
 
 		var optionsValue []optdom.OptionDTO
@@ -129,84 +118,262 @@ func NextProposal(event Event, c *Client) error {
 			optionsValue = append(optionsValue, opt)
 		}
 
-		proposalevt := ProposalEvent{
-			ID:          proposal.ID().Id,
-			Archive:     &proposal.Archive().Archive,
-			Description: &proposal.Description().Description,
-			Title:       proposal.Title().Title,
-			Options:     optionsValue,
-			LastProp:    lastProp,
-		}
+		proposalevt := c.lobby.proposalEvent(proposal, optionsValue, lastProp)
 
 		prop := Event{
 			Action:  EventNextProp,
 			Payload: marshalOrPanic(proposalevt),
 		}
 
-		for client := range c.Lobby().Clients() {
-			client.voted = false
-			if client.conn != nil {
-				client.egress <- prop
-			}
-		}
+		//si la propuesta anterior seguia abierta se cierra y se guarda su resultado antes de descartar las boletas
+		c.lobby.closeVoting(EndReasonAdmin)
+		c.lobby.clearBallots()
+		c.lobby.broadcast(prop)
+		c.lobby.openVoting(proposal.ID().Id)
 	} else {
 		log.Println("no more proposals")
 		return nil
 	}
 
-	if lastProp {
-		c.lobby.ChangeRoomState("finished")
-	}
-	
 	c.lobby.nextProposal++
+	c.lobby.saveState()
 	return nil
 }
 
-func SendMessage(event Event, c *Client) error {
-	for client := range c.Lobby().Clients() {
-		if client != c && client.conn != nil {
-			client.egress <- event
+// datos de la propuesta que se envian al abrir su votacion. En las re-votaciones de la
+// propuesta en curso solo se envian las opciones de la ronda
+func (r *RoomLobby) proposalEvent(proposal propdom.Proposal, options []optdom.OptionDTO, lastProp bool) ProposalEvent {
+	round := 1
+	if proposal.ID().Id == r.currentProposalID() {
+		var roundOptions []uint
+		round, roundOptions = r.currentRound()
+		options = filterOptions(options, roundOptions)
+	}
+
+	return ProposalEvent{
+		ID:          proposal.ID().Id,
+		Archive:     &proposal.Archive().Archive,
+		Description: &proposal.Description().Description,
+		Title:       proposal.Title().Title,
+		Options:     options,
+		LastProp:    lastProp,
+		Duration:    r.proposalDuration(),
+		BallotType:  proposal.BallotType().BallotType,
+		MinSelect:   proposal.BallotType().MinSelections,
+		MaxSelect:   proposal.BallotType().MaxSelections,
+		Rule:        proposal.DecisionRule().Rule,
+		Abstentions: proposal.DecisionRule().Abstentions,
+		VoteChange:  r.voteChangeAllowed(),
+		Round:       round,
+	}
+}
+
+// opciones incluidas en allowed, todas si allowed es nil
+func filterOptions(options []optdom.OptionDTO, allowed []uint) []optdom.OptionDTO {
+	if allowed == nil {
+		return options
+	}
+
+	var filtered []optdom.OptionDTO
+	for _, option := range options {
+		for _, id := range allowed {
+			if option.ID == id {
+				filtered = append(filtered, option)
+				break
+			}
 		}
 	}
-	return nil
+	return filtered
 }
 
 func SendResults(event Event, c *Client) error {
+	//mostrar resultados cierra la votacion de la propuesta actual
+	admin := c.lobby.canModerate(c)
+	if admin && c.lobby.isPaused() {
+		return newProtocolError(ErrCodeVotingPaused, "resume the voting first")
+	}
+	if admin {
+		c.lobby.closeVoting(EndReasonAdmin)
+	}
+
+	//con la votacion abierta los resultados parciales mostrarian como va la votacion
+	if !admin && c.lobby.isVotingOpen() {
+		return newProtocolError(ErrCodeForbidden, "results are available when the voting closes")
+	}
+
+	c.lobby.sendResults()
+	c.lobby.publish(busResults, nil)
+
+	return nil
+}
+
+// calcula los resultados de la propuesta actual sobre una copia de las boletas
+func (r *RoomLobby) tallyResults() (ResultsEvent, error) {
+	casts, err := r.castBallots()
+	if err != nil {
+		return ResultsEvent{}, err
+	}
+
+	proposal := r.currentProposal()
+	var ballotType propv.BallotType
+	if proposal != nil {
+		ballotType = proposal.BallotType()
+	}
+
 	//armo el json con los votos
 	var userVotes []UserVoteEvent
-	for client, vote := range c.Lobby().results {
-		voterData := VoterData{
-			Username: client.User.Username().Username,
-			ID:       client.User.ID().Id,
+	var ballots []propdom.Ballot
+	var allBallots []propdom.Ballot
+	approvals := make(map[uint]int)
+	var abstain, blank propdom.ChoiceResults
+	for _, cast := range casts {
+		votes := cast.votes
+		if len(votes) == 0 {
+			continue
 		}
 
 		userVote := UserVoteEvent{
-			From:     voterData,
-			OptionId: vote.OptionID().Id,
+			From:     cast.voter,
+			OptionId: votes[0].OptionID().Id,
+			Proxy:    cast.proxy,
+			Choice:   votes[0].Choice(),
+		}
+
+		//abstenciones y votos en blanco se cuentan aparte de las opciones
+		if votes[0].IsAbstention() {
+			userVote.Weight = votes[0].Weight()
+			if votes[0].Choice() == votedom.ChoiceAbstain {
+				abstain.Count++
+				abstain.Weight += votes[0].Weight()
+			} else {
+				blank.Count++
+				blank.Weight += votes[0].Weight()
+			}
+			userVotes = append(userVotes, userVote)
+			continue
+		}
+
+		//los totales suman el peso con el que voto cada usuario
+		ballot := propdom.Ballot{Weight: votes[0].Weight()}
+		for _, vote := range votes {
+			ballot.Options = append(ballot.Options, vote.OptionID().Id)
+			approvals[vote.OptionID().Id] += vote.Weight()
+		}
+		userVote.Weight = ballot.Weight
+		allBallots = append(allBallots, ballot)
+
+		if ballotType.IsRanked() {
+			userVote.Ranking = ballot.Options
+			ballots = append(ballots, ballot)
+		} else if ballotType.IsMultiSelect() {
+			userVote.Options = ballot.Options
 		}
 
 		userVotes = append(userVotes, userVote)
 	}
 
+	//la propuesta es valida solo si votaron al menos tantos usuarios como pide el quorum
+	required := r.requiredQuorum()
+	counted := len(userVotes)
+	if !r.abstainCountsForQuorum() {
+		counted -= abstain.Count + blank.Count
+	}
+	resultsEvt := ResultsEvent{
+		Votes:    userVotes,
+		Valid:    counted >= required,
+		Cast:     len(userVotes),
+		Required: required,
+		Abstain:  abstain,
+		Blank:    blank,
+	}
+
+	round, roundOptions := r.currentRound()
+	resultsEvt.Round = round
+
+	secret := r.isSecret()
+	if proposal != nil {
+		options, err := r.optRepo.GetByProposal(proposal.ID())
+		if err != nil {
+			log.Println(err.Error())
+		} else {
+			var optionIds []uint
+			for _, option := range options {
+				optionIds = append(optionIds, option.ID().Id)
+			}
+			if roundOptions != nil {
+				optionIds = roundOptions
+			}
+
+			if ballotType.IsRanked() {
+				//propuesta ranked: se resuelve por segunda vuelta instantanea
+				runoff := propdom.InstantRunoff(optionIds, ballots)
+				resultsEvt.Runoff = &runoff
+			}
+			for _, optionId := range optionIds {
+				resultsEvt.Approvals = append(resultsEvt.Approvals, propdom.OptionTally{OptionId: optionId, Votes: approvals[optionId]})
+			}
+
+			tally := propdom.NewTally(ballotType, allBallots, resultsEvt.Approvals, resultsEvt.Runoff)
+			tally.Cast += blank.Weight
+			tally.Abstentions = abstain.Weight
+			tally.Eligible = r.whitelistWeight
+			tally.QuorumMet = resultsEvt.Valid
+
+			outcome := propdom.Decide(proposal.DecisionRule(), tally)
+			resultsEvt.Outcome = &outcome
+		}
+	}
+
+	//votacion secreta: no se informa quien voto que, solo los totales
+	if secret {
+		resultsEvt.Secret = true
+		resultsEvt.Votes = nil
+	}
+
+	return resultsEvt, nil
+}
+
+// guarda el resultado de la propuesta actual, se llama al cerrarse su votacion
+func (r *RoomLobby) saveOutcome() {
+	proposal := r.currentProposal()
+	if proposal == nil {
+		return
+	}
+
+	results, err := r.tallyResults()
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	if results.Outcome == nil {
+		return
+	}
+	if err := r.propRepo.SaveOutcome(proposal.ID(), r.session, results.Round, *results.Outcome); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// envia los resultados de la propuesta actual a los clientes de esta instancia
+func (r *RoomLobby) sendResults() {
+	resultsEvt, err := r.tallyResults()
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
 	evt := Event{
 		Action:  EventResults,
-		Payload: marshalOrPanic(userVotes),
+		Payload: marshalOrPanic(resultsEvt),
 	}
 
 	log.Println(evt)
-	for client := range c.Lobby().Clients() {
+	for client := range r.Clients() {
 		if client.conn != nil {
 			client.egress <- evt
 			log.Println("resultados enviados a " + client.User.Username().Username)
 		} else {
 			log.Println("A este no porque se fue " + client.User.Username().Username)
 		}
-		
-	}
 
-	if c.lobby.nextProposal >= len(c.Lobby().proposals)-1  {
-		c.lobby.ChangeRoomState("finished")
 	}
-
-	return nil
+	r.stream(evt)
 }
@@ -0,0 +1,95 @@
+package socketStructs
+
+import (
+	"encoding/json"
+
+	v "suffgo/internal/rooms/domain/valueObjects"
+)
+
+// congela la cuenta regresiva y deja de aceptar votos hasta que se reanude la sala
+func PauseVoting(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	var pause PauseVotingEvent
+	if len(event.Payload) > 0 {
+		if err := json.Unmarshal(event.Payload, &pause); err != nil {
+			return newProtocolError(ErrCodeInvalidPayload, err.Error())
+		}
+	}
+
+	if c.lobby.room.State().CurrentState != v.StateInProgress {
+		return newProtocolError(ErrCodeInvalidState, "only a room in progress can be paused")
+	}
+
+	reason := pause.Reason
+	if reason == "" {
+		reason = "voting paused"
+	}
+
+	c.lobby.votingmx.Lock()
+	c.lobby.stopTimerLocked()
+	c.lobby.votingmx.Unlock()
+
+	if err := c.lobby.ChangeRoomState(v.StatePaused, c, reason); err != nil {
+		c.lobby.resumeTimer()
+		return newProtocolError(ErrCodeInternal, "error pausing the room")
+	}
+
+	c.lobby.persistState()
+	c.lobby.broadcast(c.lobby.pauseEvent(pause.Reason))
+	return nil
+}
+
+// la cuenta regresiva sigue desde donde se pauso, en la instancia que reanuda la sala
+func ResumeVoting(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	if !c.lobby.isPaused() {
+		return newProtocolError(ErrCodeInvalidState, "the room is not paused")
+	}
+
+	if err := c.lobby.ChangeRoomState(v.StateInProgress, c, "voting resumed"); err != nil {
+		return newProtocolError(ErrCodeInternal, "error resuming the room")
+	}
+
+	c.lobby.resumeTimer()
+	c.lobby.saveState()
+	c.lobby.broadcast(c.lobby.pauseEvent(""))
+	return nil
+}
+
+func (r *RoomLobby) isPaused() bool {
+	return r.room.State().CurrentState == v.StatePaused
+}
+
+func (r *RoomLobby) resumeTimer() {
+	r.votingmx.RLock()
+	open, proposalID, remaining := r.votingOpen, r.votingProposal, r.remaining
+	r.votingmx.RUnlock()
+
+	if open {
+		r.openVotingFor(proposalID, remaining)
+	}
+}
+
+// pause_voting si la sala esta pausada, resume_voting si no
+func (r *RoomLobby) pauseEvent(reason string) Event {
+	paused := PausedEvent{Paused: r.isPaused(), Reason: reason}
+
+	r.votingmx.RLock()
+	paused.ProposalID = r.votingProposal
+	if r.votingOpen {
+		paused.Remaining = r.remaining
+	}
+	r.votingmx.RUnlock()
+
+	action := EventResumeVoting
+	if paused.Paused {
+		action = EventPauseVoting
+	}
+	return Event{Action: action, Payload: marshalOrPanic(paused)}
+}
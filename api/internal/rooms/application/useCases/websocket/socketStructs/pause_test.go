@@ -0,0 +1,119 @@
+package socketStructs
+
+import (
+	"testing"
+
+	optdom "suffgo/internal/options/domain"
+	propdom "suffgo/internal/proposals/domain"
+	propv "suffgo/internal/proposals/domain/valueObjects"
+	"suffgo/internal/rooms/domain"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	sv "suffgo/internal/shared/domain/valueObjects"
+	userdom "suffgo/internal/users/domain"
+	userv "suffgo/internal/users/domain/valueObjects"
+)
+
+// los repositorios solo implementan lo que usa la votacion, el resto de los metodos no se llaman
+type stubRoomRepo struct {
+	domain.RoomRepository
+}
+
+func (s *stubRoomRepo) ChangeState(transition domain.StateTransition) error { return nil }
+
+func (s *stubRoomRepo) SaveLobbyState(roomId sv.ID, state domain.LobbyState) error { return nil }
+
+type stubProposalRepo struct {
+	propdom.ProposalRepository
+	outcomes []uint
+}
+
+func (s *stubProposalRepo) SaveOutcome(proposalID sv.ID, sessionID sv.ID, round int, outcome propdom.Outcome) error {
+	s.outcomes = append(s.outcomes, proposalID.Id)
+	return nil
+}
+
+type stubOptionRepo struct {
+	optdom.OptionRepository
+}
+
+func (s *stubOptionRepo) GetByProposal(id sv.ID) ([]optdom.Option, error) { return nil, nil }
+
+func newTestLobby(t *testing.T, proposals int) (*RoomLobby, *Client, *stubProposalRepo) {
+	t.Helper()
+
+	roomID, _ := sv.NewID(1)
+	adminID, _ := sv.NewID(1)
+	state, _ := v.NewState(v.StateOnline)
+	room := domain.NewRoom(roomID, v.IsFormal{}, nil, v.Name{}, adminID, v.Description{}, nil, state)
+
+	var props []propdom.Proposal
+	for i := 1; i <= proposals; i++ {
+		id, _ := sv.NewID(uint(i))
+		props = append(props, *propdom.NewProposal(id, &propv.Archive{}, propv.Title{}, &propv.Description{}, propv.BallotType{}, propv.DecisionRule{}, roomID))
+	}
+
+	propRepo := &stubProposalRepo{}
+	lobby := &RoomLobby{
+		votesProcesing: make(chan struct{}, 1),
+		clients:        make(ClientList),
+		room:           room,
+		proposals:      props,
+		propRepo:       propRepo,
+		roomRepo:       &stubRoomRepo{},
+		optRepo:        &stubOptionRepo{},
+		results:        make(map[uint]castBallot),
+		round:          1,
+		remote:         make(map[string]map[uint]remoteClient),
+	}
+	lobby.votesProcesing <- struct{}{}
+
+	admin := NewClient(nil, *userdom.NewUser(adminID, userv.FullName{}, userv.UserName{}, userv.Dni{}, userv.Email{}, userv.Password{}, nil), Heartbeat{})
+	admin.SetLobby(lobby)
+	return lobby, admin, propRepo
+}
+
+func TestPauseDuringLastProposal(t *testing.T) {
+	lobby, admin, propRepo := newTestLobby(t, 2)
+
+	if err := StartVoting(Event{}, admin); err != nil {
+		t.Fatalf("start voting: %v", err)
+	}
+	if err := NextProposal(Event{}, admin); err != nil {
+		t.Fatalf("next proposal: %v", err)
+	}
+	if state := lobby.room.State().CurrentState; state != v.StateInProgress {
+		t.Fatalf("room should stay in progress while the last proposal is voted, got %s", state)
+	}
+
+	if err := PauseVoting(Event{}, admin); err != nil {
+		t.Fatalf("pause during the last proposal: %v", err)
+	}
+	if !lobby.isPaused() {
+		t.Fatal("room should be paused")
+	}
+	if err := ResumeVoting(Event{}, admin); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	lobby.closeVoting(EndReasonTimeout)
+	if state := lobby.room.State().CurrentState; state != v.StateFinished {
+		t.Fatalf("closing the last proposal should finish the room, got %s", state)
+	}
+	if len(propRepo.outcomes) != 2 || propRepo.outcomes[1] != 2 {
+		t.Fatalf("outcomes should be saved for both proposals, got %v", propRepo.outcomes)
+	}
+}
+
+func TestPauseSingleProposal(t *testing.T) {
+	lobby, admin, _ := newTestLobby(t, 1)
+
+	if err := StartVoting(Event{}, admin); err != nil {
+		t.Fatalf("start voting: %v", err)
+	}
+	if err := PauseVoting(Event{}, admin); err != nil {
+		t.Fatalf("pause the only proposal: %v", err)
+	}
+	if !lobby.isPaused() {
+		t.Fatal("room should be paused")
+	}
+}
@@ -0,0 +1,179 @@
+package socketStructs
+
+import (
+	"encoding/json"
+	"log"
+	propdom "suffgo/internal/proposals/domain"
+	v "suffgo/internal/rooms/domain/valueObjects"
+	"time"
+)
+
+const (
+	EndReasonTimeout = "timeout"
+	EndReasonAdmin   = "admin"
+)
+
+// cuenta regresiva de la propuesta en curso, la maneja el servidor
+type proposalTimer struct {
+	proposalID uint
+	remaining  int
+	stop       chan struct{}
+}
+
+// abre la votacion de la propuesta y, si la sala tiene ProposalTimer, arranca la cuenta regresiva
+func (r *RoomLobby) openVoting(proposalID uint) {
+	r.openVotingFor(proposalID, r.proposalDuration())
+}
+
+// abre la votacion con la cuenta regresiva indicada en segundos (0 = sin limite)
+func (r *RoomLobby) openVotingFor(proposalID uint, duration int) {
+	r.votingmx.Lock()
+	defer r.votingmx.Unlock()
+
+	r.stopTimerLocked()
+	//una propuesta nueva empieza por la primera ronda
+	if proposalID != r.votingProposal {
+		r.round = 1
+		r.roundOptions = nil
+	}
+	r.votingOpen = true
+	r.votingProposal = proposalID
+	r.remaining = duration
+
+	if duration <= 0 {
+		return
+	}
+
+	t := &proposalTimer{
+		proposalID: proposalID,
+		remaining:  duration,
+		stop:       make(chan struct{}),
+	}
+	r.timer = t
+
+	go r.runProposalTimer(t)
+}
+
+func (r *RoomLobby) runProposalTimer(t *proposalTimer) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			r.votingmx.Lock()
+			if r.timer != t {
+				r.votingmx.Unlock()
+				return
+			}
+			t.remaining--
+			remaining := t.remaining
+			r.remaining = remaining
+			r.votingmx.Unlock()
+
+			r.broadcast(Event{
+				Action:  EventTimerTick,
+				Payload: marshalOrPanic(TimerTickEvent{ProposalID: t.proposalID, Remaining: remaining}),
+			})
+
+			if remaining <= 0 {
+				log.Printf("voting time is over for proposal id = %d \n", t.proposalID)
+				r.closeVoting(EndReasonTimeout)
+				return
+			}
+		}
+	}
+}
+
+// las replicas siguen la cuenta regresiva con los timer_tick para poder pausarla
+func (r *RoomLobby) trackTimer(event Event) {
+	if event.Action != EventTimerTick || r.isPaused() {
+		return
+	}
+
+	var tick TimerTickEvent
+	if err := json.Unmarshal(event.Payload, &tick); err != nil {
+		return
+	}
+
+	r.votingmx.Lock()
+	if tick.ProposalID == r.votingProposal {
+		r.remaining = tick.Remaining
+	}
+	r.votingmx.Unlock()
+}
+
+// cierra la votacion en curso, guarda su resultado e informa a todos los clientes con end_voting
+func (r *RoomLobby) closeVoting(reason string) {
+	r.votingmx.Lock()
+	if !r.votingOpen {
+		r.votingmx.Unlock()
+		return
+	}
+	r.votingOpen = false
+	r.stopTimerLocked()
+	proposalID := r.votingProposal
+	r.votingmx.Unlock()
+
+	r.saveOutcome()
+	r.broadcast(Event{
+		Action:  EventEndVoting,
+		Payload: marshalOrPanic(EndVotingEvent{ProposalID: proposalID, Reason: reason}),
+	})
+
+	//la sala queda finished al cerrarse la votacion de la ultima propuesta, hasta entonces se puede pausar
+	if r.nextProposal >= len(r.proposals) && r.room.State().CurrentState == v.StateInProgress {
+		r.ChangeRoomState(v.StateFinished, nil, "voting of the last proposal closed")
+	}
+	r.saveState()
+}
+
+// propuesta que se esta votando (o la ultima votada), nil si todavia no empezo
+func (r *RoomLobby) currentProposal() *propdom.Proposal {
+	r.votingmx.RLock()
+	proposalID := r.votingProposal
+	r.votingmx.RUnlock()
+
+	for i := range r.proposals {
+		if r.proposals[i].ID().Id == proposalID {
+			return &r.proposals[i]
+		}
+	}
+	return nil
+}
+
+func (r *RoomLobby) currentProposalID() uint {
+	r.votingmx.RLock()
+	defer r.votingmx.RUnlock()
+	return r.votingProposal
+}
+
+// ronda de la propuesta en curso y sus opciones, nil si se votan todas
+func (r *RoomLobby) currentRound() (int, []uint) {
+	r.votingmx.RLock()
+	defer r.votingmx.RUnlock()
+	return r.round, r.roundOptions
+}
+
+func (r *RoomLobby) isVotingOpen() bool {
+	r.votingmx.RLock()
+	defer r.votingmx.RUnlock()
+	return r.votingOpen
+}
+
+// debe llamarse con votingmx tomado
+func (r *RoomLobby) stopTimerLocked() {
+	if r.timer != nil {
+		close(r.timer.stop)
+		r.timer = nil
+	}
+}
+
+func (r *RoomLobby) proposalDuration() int {
+	if r.settings == nil {
+		return 0
+	}
+	return r.settings.ProposalTimer().ProposalTimer
+}
@@ -0,0 +1,112 @@
+package socketStructs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// versiones del protocolo del websocket. La version 1 es la original: sin ack/nack
+// y las acciones desconocidas se ignoran
+const (
+	ProtocolV1         = 1
+	ProtocolV2         = 2
+	ProtocolVersion    = ProtocolV2 //version mas nueva que soporta el servidor
+	MinProtocolVersion = ProtocolV1
+)
+
+// codigos de error que acompañan a los eventos error y nack
+const (
+	ErrCodeInvalidPayload   = "invalid_payload"
+	ErrCodeUnknownAction    = "unknown_action"
+	ErrCodeNotAdmitted      = "not_admitted" //el cliente sigue en la lista de espera
+	ErrCodeForbidden        = "forbidden"
+	ErrCodeQuorumNotReached = "quorum_not_reached"
+	ErrCodeVotingClosed     = "voting_closed"
+	ErrCodeVotingPaused     = "voting_paused"
+	ErrCodeInvalidState     = "invalid_state" //la sala no esta en un estado que permita la accion
+	ErrCodeNoProposal       = "no_proposal"
+	ErrCodeObserver         = "observer_cannot_vote"
+	ErrCodeNotRepresented   = "not_represented"
+	ErrCodeVoteDelegated    = "vote_delegated"
+	ErrCodeInvalidBallot    = "invalid_ballot"
+	ErrCodeInvalidMessage   = "invalid_message"
+	ErrCodeMuted            = "muted"
+	ErrCodeRateLimited      = "rate_limited"
+	ErrCodeMessageNotFound  = "message_not_found"
+	ErrCodeInternal         = "internal_error"
+)
+
+// error que se informa al cliente sin cerrar la conexion
+type ProtocolError struct {
+	Code    string
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func newProtocolError(code, message string) *ProtocolError {
+	return &ProtocolError{Code: code, Message: message}
+}
+
+// elige la version a usar con el cliente. Si pide una mas nueva se usa la del servidor,
+// 0 equivale a un cliente que no indico version
+func NegotiateVersion(requested int) (int, bool) {
+	switch {
+	case requested == 0:
+		return ProtocolV1, true
+	case requested < MinProtocolVersion:
+		return 0, false
+	case requested > ProtocolVersion:
+		return ProtocolVersion, true
+	}
+	return requested, true
+}
+
+// responde al evento segun la version del cliente: en v2 los eventos con id reciben ack o nack,
+// los errores sin id se envian como evento error. Devuelve el error si no es un error de protocolo
+func (c *Client) reply(event Event, err error) error {
+	var protoErr *ProtocolError
+	if err != nil && !errors.As(err, &protoErr) {
+		return err
+	}
+
+	if c.version < ProtocolV2 {
+		//v1 no conoce las acciones desconocidas ni los acks
+		if protoErr != nil && protoErr.Code != ErrCodeUnknownAction && protoErr.Code != ErrCodeNotAdmitted {
+			c.sendError(protoErr)
+		}
+		return nil
+	}
+
+	switch {
+	case event.ID == "" && protoErr != nil:
+		c.sendError(protoErr)
+	case event.ID == "":
+	case protoErr != nil:
+		c.egress <- Event{
+			Action: EventNack,
+			ID:     event.ID,
+			Payload: marshalOrPanic(NackEvent{
+				Action:  event.Action,
+				Code:    protoErr.Code,
+				Message: protoErr.Message,
+			}),
+		}
+	default:
+		c.egress <- Event{
+			Action:  EventAck,
+			ID:      event.ID,
+			Payload: marshalOrPanic(AckEvent{Action: event.Action}),
+		}
+	}
+	return nil
+}
+
+func (c *Client) sendError(err *ProtocolError) {
+	c.egress <- Event{
+		Action:  EventError,
+		Payload: marshalOrPanic(ErrorEvent{Code: err.Code, Message: err.Message}),
+	}
+}
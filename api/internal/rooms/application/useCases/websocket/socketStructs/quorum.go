@@ -0,0 +1,42 @@
+package socketStructs
+
+// usuarios minimos requeridos segun la configuracion de la sala
+func (r *RoomLobby) requiredQuorum() int {
+	if r.settings == nil {
+		return 0
+	}
+	return r.settings.Quorum().Required(r.whitelistSize)
+}
+
+// sin configuracion las abstenciones y votos en blanco cuentan para el quorum
+func (r *RoomLobby) abstainCountsForQuorum() bool {
+	return r.settings == nil || r.settings.AbstainQuorum().Enabled()
+}
+
+// el quorum se calcula sobre la whitelist: solo cuentan los usuarios de la whitelist con la conexion
+// abierta, tambien en las salas publicas. Los que esperan reconectarse no estan presentes.
+// Debe llamarse con clientsmx tomado
+func (r *RoomLobby) quorumStatus() QuorumStatus {
+	present := 0
+	for client := range r.clients {
+		if client.conn != nil && client.whitelisted && !r.IsObserver(client.User.ID().Id) {
+			present++
+		}
+	}
+	for _, remotes := range r.remote {
+		for id, remote := range remotes {
+			if remote.Client.Connected && remote.Whitelisted && !r.IsObserver(id) {
+				present++
+			}
+		}
+	}
+
+	required := r.requiredQuorum()
+
+	return QuorumStatus{
+		Required:  required,
+		Present:   present,
+		Whitelist: r.whitelistSize,
+		Reached:   present >= required,
+	}
+}
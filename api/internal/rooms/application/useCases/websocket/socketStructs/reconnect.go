@@ -0,0 +1,135 @@
+package socketStructs
+
+import (
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// tiempo que se reserva el lugar de un participante despues de un corte de conexion
+const ReconnectGrace = 30 * time.Second
+
+// la conexion se corto sin que el usuario cierre la sala: se conserva su lugar (y su voto) durante
+// ReconnectGrace. Si conn ya no es la conexion actual (ej: se reemplazo al reconectarse) no hace nada
+func (r *RoomLobby) disconnectClient(client *Client, conn *websocket.Conn) {
+	r.clientsmx.Lock()
+	if _, ok := r.clients[client]; !ok || client.conn != conn {
+		queued := r.isQueued(client)
+		r.clientsmx.Unlock()
+		//los usuarios en lista de espera no tienen lugar que reservar
+		if queued {
+			r.removeClient(client)
+		}
+		return
+	}
+
+	conn.Close()
+	client.conn = nil
+	r.clients[client] = false
+	close(client.detached)
+	client.grace = time.AfterFunc(ReconnectGrace, func() {
+		r.expireClient(client)
+	})
+
+	r.broadcastClientList()
+	r.clientsmx.Unlock()
+
+	r.publishPresence(client, false)
+	log.Printf("user %s disconnected, waiting %s to reconnect \n", client.User.Username().Username, ReconnectGrace)
+}
+
+// vencio el periodo de reconexion sin que el usuario vuelva
+func (r *RoomLobby) expireClient(client *Client) {
+	r.clientsmx.RLock()
+	_, ok := r.clients[client]
+	expired := ok && client.conn == nil
+	r.clientsmx.RUnlock()
+
+	if expired {
+		log.Printf("user %s did not reconnect \n", client.User.Username().Username)
+		r.removeClient(client)
+	}
+}
+
+// vuelve a asociar al usuario con su lugar en la sala. Si la conexion anterior seguia abierta se reemplaza
+func (r *RoomLobby) Resume(client *Client, conn *websocket.Conn, version int) bool {
+	r.clientsmx.Lock()
+	defer r.clientsmx.Unlock()
+
+	if _, ok := r.clients[client]; !ok {
+		return false
+	}
+
+	if client.grace != nil {
+		client.grace.Stop()
+		client.grace = nil
+	}
+	if client.conn != nil {
+		close(client.detached)
+		client.conn.Close()
+	}
+
+	client.conn = conn
+	client.version = version
+	client.detached = make(chan struct{})
+	r.clients[client] = true
+
+	log.Printf("user %s reconnected \n", client.User.Username().Username)
+	return true
+}
+
+// reenvia al usuario reconectado la propuesta en curso y el estado de la sala. Debe llamarse con WriteMessages corriendo
+func (r *RoomLobby) ReplayTo(client *Client) {
+	resume := ResumeEvent{
+		ResumeToken: client.resumeToken,
+		VotingOpen:  r.isVotingOpen(),
+		Voted:       client.voted,
+		Paused:      r.isPaused(),
+	}
+
+	r.votingmx.RLock()
+	if r.timer != nil || resume.Paused {
+		resume.Remaining = r.remaining
+	}
+	r.votingmx.RUnlock()
+
+	resume.Proposal = r.currentProposalEvent()
+
+	client.egress <- Event{
+		Action:  EventResume,
+		Payload: marshalOrPanic(resume),
+	}
+	r.sendChatHistory(client)
+
+	r.clientsmx.RLock()
+	r.broadcastClientList()
+	r.clientsmx.RUnlock()
+	r.publishPresence(client, false)
+}
+
+// debe llamarse con clientsmx tomado
+func (r *RoomLobby) isQueued(client *Client) bool {
+	for _, queued := range r.waiting {
+		if queued == client {
+			return true
+		}
+	}
+	return false
+}
+
+// debe llamarse con WriteMessages corriendo
+func (r *RoomLobby) sendSession(client *Client) {
+	client.egress <- Event{
+		Action: EventSession,
+		Payload: marshalOrPanic(SessionEvent{
+			ResumeToken: client.resumeToken,
+			Grace:       int(ReconnectGrace.Seconds()),
+			Version:     client.version,
+		}),
+	}
+	r.sendChatHistory(client)
+	if r.isPaused() {
+		client.egress <- r.pauseEvent("")
+	}
+}
@@ -0,0 +1,136 @@
+package socketStructs
+
+import (
+	"encoding/json"
+	"log"
+
+	propdom "suffgo/internal/proposals/domain"
+	v "suffgo/internal/rooms/domain/valueObjects"
+)
+
+// vuelve a abrir la propuesta en curso en una ronda nueva, por ejemplo para desempatar.
+// Con top solo se votan las opciones mas votadas de la ronda anterior
+func Revote(event Event, c *Client) error {
+	if !c.lobby.canModerate(c) {
+		return newProtocolError(ErrCodeForbidden, "lack of privileges")
+	}
+
+	var revote RevoteEvent
+	if len(event.Payload) > 0 {
+		if err := json.Unmarshal(event.Payload, &revote); err != nil {
+			return newProtocolError(ErrCodeInvalidPayload, err.Error())
+		}
+	}
+	if revote.Top < 0 || revote.Top == 1 {
+		return newProtocolError(ErrCodeInvalidPayload, "top must be 0 or at least 2")
+	}
+
+	if c.lobby.isPaused() {
+		return newProtocolError(ErrCodeVotingPaused, "resume the voting first")
+	}
+
+	state := c.lobby.room.State().CurrentState
+	if state != v.StateInProgress && state != v.StateFinished {
+		return newProtocolError(ErrCodeInvalidState, "the voting has not started")
+	}
+
+	//se re-vota despues de ver los resultados de la ronda
+	if c.lobby.isVotingOpen() {
+		return newProtocolError(ErrCodeInvalidState, "close the voting before a revote")
+	}
+
+	proposal := c.lobby.currentProposal()
+	if proposal == nil {
+		return newProtocolError(ErrCodeNoProposal, "there is no proposal to vote again")
+	}
+
+	round, roundOptions := c.lobby.currentRound()
+
+	var options []uint
+	if revote.Top > 0 {
+		tallies, err := c.lobby.roundTally(*proposal, roundOptions)
+		if err != nil {
+			log.Println(err.Error())
+			return newProtocolError(ErrCodeInternal, "error fetching options")
+		}
+		options = propdom.TopOptions(tallies, revote.Top)
+	}
+
+	reason := revote.Reason
+	if reason == "" {
+		reason = "revote"
+	}
+
+	saved, err := c.lobby.propRepo.SaveRound(propdom.Round{
+		ProposalID: proposal.ID().Id,
+		SessionID:  c.lobby.session.Id,
+		Number:     round + 1,
+		Options:    options,
+		Reason:     reason,
+	})
+	if err != nil {
+		log.Println(err.Error())
+		return newProtocolError(ErrCodeInternal, "error saving the round")
+	}
+
+	//la ultima propuesta deja la sala finished al cerrarse su votacion
+	if state == v.StateFinished {
+		if err := c.lobby.ChangeRoomState(v.StateInProgress, c, reason); err != nil {
+			return newProtocolError(ErrCodeInternal, "error reopening the room")
+		}
+	}
+
+	c.lobby.votingmx.Lock()
+	c.lobby.round = saved.Number
+	c.lobby.roundOptions = saved.Options
+	c.lobby.votingmx.Unlock()
+
+	c.lobby.clearBallots()
+
+	c.lobby.broadcast(Event{
+		Action:  EventRevote,
+		Payload: marshalOrPanic(c.lobby.currentProposalEvent()),
+	})
+	c.lobby.openVoting(proposal.ID().Id)
+	c.lobby.saveState()
+
+	return nil
+}
+
+// peso de los votos de cada opcion en la ronda actual. En las ranked cuenta la primera preferencia
+func (r *RoomLobby) roundTally(proposal propdom.Proposal, roundOptions []uint) ([]propdom.OptionTally, error) {
+	candidates := roundOptions
+	if candidates == nil {
+		options, err := r.optRepo.GetByProposal(proposal.ID())
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range options {
+			candidates = append(candidates, option.ID().Id)
+		}
+	}
+
+	casts, err := r.castBallots()
+	if err != nil {
+		return nil, err
+	}
+
+	votes := make(map[uint]int, len(candidates))
+	for _, cast := range casts {
+		for _, vote := range cast.votes {
+			if vote.IsAbstention() {
+				continue
+			}
+			if proposal.BallotType().IsRanked() && vote.Rank() > 1 {
+				continue
+			}
+			votes[vote.OptionID().Id] += vote.Weight()
+		}
+	}
+
+	tallies := make([]propdom.OptionTally, 0, len(candidates))
+	for _, optionID := range candidates {
+		tallies = append(tallies, propdom.OptionTally{OptionId: optionID, Votes: votes[optionID]})
+	}
+	return tallies, nil
+}
@@ -0,0 +1,57 @@
+package socketStructs
+
+import (
+	"log"
+
+	v "suffgo/internal/rooms/domain/valueObjects"
+)
+
+func (r *RoomLobby) loadRoles() {
+	roles, err := r.roomRepo.GetRoles(r.room.ID())
+	if err != nil {
+		log.Printf("error loading roles of room id = %d: %v \n", r.room.ID().Id, err)
+		roles = make(map[uint]string)
+	}
+
+	r.Lock()
+	r.roles = roles
+	r.Unlock()
+}
+
+// el dueño es el admin de la sala, el resto de los usuarios sin rol asignado son voter
+func (r *RoomLobby) role(userID uint) string {
+	if userID == r.room.AdminID().Id {
+		return v.RoleOwner
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+	if role, ok := r.roles[userID]; ok {
+		return role
+	}
+	return v.RoleVoter
+}
+
+func (r *RoomLobby) IsObserver(userID uint) bool {
+	return r.role(userID) == v.RoleObserver
+}
+
+// owner y co_admin manejan la votacion, pueden estar conectados a cualquier instancia
+func (r *RoomLobby) canModerate(c *Client) bool {
+	return v.Role{Role: r.role(c.User.ID().Id)}.CanModerate()
+}
+
+// vuelve a leer el dueño y los roles despues de un cambio hecho desde la api
+func (r *RoomLobby) ReloadRoles() {
+	room, err := r.roomRepo.GetByID(r.room.ID())
+	if err != nil || room == nil {
+		log.Printf("error reloading room id = %d: %v \n", r.room.ID().Id, err)
+	} else {
+		r.room.SetAdminID(room.AdminID())
+	}
+	r.loadRoles()
+
+	r.clientsmx.RLock()
+	r.broadcastClientList()
+	r.clientsmx.RUnlock()
+}
@@ -0,0 +1,207 @@
+package socketStructs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// payload de cada evento que envia el cliente
+var inboundEvents = map[string]interface{}{
+	EventSendMessage:   NewMessageEvent{},
+	EventStartVoting:   nil,
+	EventVote:          VoteEvent{},
+	EventResults:       nil,
+	EventNextProp:      nil,
+	EventKickUser:      KickUserEvent{},
+	EventMuteUser:      MuteUserEvent{},
+	EventUnmuteUser:    MuteUserEvent{},
+	EventDeleteMessage: DeleteMessageEvent{},
+	EventPauseVoting:   PauseVotingEvent{},
+	EventResumeVoting:  nil,
+	EventRevote:        RevoteEvent{},
+}
+
+// payload de cada evento que envia el servidor
+var outboundEvents = map[string]interface{}{
+	EventSendMessage:      SendMessageEvent{},
+	EventUpdateClientList: UpdateClientListEvent{},
+	EventFirstProp:        ProposalEvent{},
+	EventNextProp:         ProposalEvent{},
+	EventEndVoting:        EndVotingEvent{},
+	EventResults:          ResultsEvent{},
+	EventError:            ErrorEvent{},
+	EventKickUser:         ErrorEvent{},
+	EventKickInfoUser:     ErrorEvent{},
+	EventTimerTick:        TimerTickEvent{},
+	EventWaitingList:      WaitingListEvent{},
+	EventSession:          SessionEvent{},
+	EventResume:           ResumeEvent{},
+	EventAck:              AckEvent{},
+	EventNack:             NackEvent{},
+	EventMuteUser:         MutedEvent{},
+	EventUnmuteUser:       MutedEvent{},
+	EventDeleteMessage:    DeleteMessageEvent{},
+	EventChatHistory:      ChatHistoryEvent{},
+	EventRoomClosed:       RoomClosedEvent{},
+	EventPauseVoting:      PausedEvent{},
+	EventResumeVoting:     PausedEvent{},
+	EventRevote:           ProposalEvent{},
+}
+
+var errorCodes = []string{
+	ErrCodeInvalidPayload,
+	ErrCodeUnknownAction,
+	ErrCodeNotAdmitted,
+	ErrCodeForbidden,
+	ErrCodeQuorumNotReached,
+	ErrCodeVotingClosed,
+	ErrCodeVotingPaused,
+	ErrCodeInvalidState,
+	ErrCodeNoProposal,
+	ErrCodeObserver,
+	ErrCodeNotRepresented,
+	ErrCodeVoteDelegated,
+	ErrCodeInvalidBallot,
+	ErrCodeInvalidMessage,
+	ErrCodeMuted,
+	ErrCodeRateLimited,
+	ErrCodeMessageNotFound,
+	ErrCodeInternal,
+}
+
+type schema map[string]interface{}
+
+// JSON Schema (draft 2020-12) de todos los eventos del websocket, generado a partir de los structs de Event.go
+func ProtocolSchema() map[string]interface{} {
+	gen := &schemaGenerator{defs: make(schema), names: make(map[reflect.Type]string)}
+
+	return schema{
+		"$schema":                "https://json-schema.org/draft/2020-12/schema",
+		"title":                  "suffgo room websocket protocol",
+		"x-protocol-version":     ProtocolVersion,
+		"x-min-protocol-version": MinProtocolVersion,
+		"x-error-codes":          errorCodes,
+		"$defs":                  gen.defs,
+		"properties": schema{
+			"inbound":  gen.events(inboundEvents),
+			"outbound": gen.events(outboundEvents),
+		},
+	}
+}
+
+type schemaGenerator struct {
+	defs  schema
+	names map[reflect.Type]string
+}
+
+// un esquema de sobre por accion: action fija, el payload del evento, id y version
+func (g *schemaGenerator) events(events map[string]interface{}) schema {
+	var envelopes []schema
+	for _, action := range sortedKeys(events) {
+		payload := schema{"type": "null"}
+		if events[action] != nil {
+			payload = g.schemaOf(reflect.TypeOf(events[action]))
+		}
+
+		envelopes = append(envelopes, schema{
+			"type":  "object",
+			"title": action,
+			"properties": schema{
+				"action":  schema{"const": action},
+				"payload": payload,
+				"id":      schema{"type": "string"},
+				"version": schema{"type": "integer"},
+			},
+			"required": []string{"action"},
+		})
+	}
+	return schema{"oneOf": envelopes}
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) schema {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return schema{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(json.RawMessage{}):
+		return schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schema{"anyOf": []schema{g.schemaOf(t.Elem()), {"type": "null"}}}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return schema{"type": []string{"array", "null"}, "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return schema{"$ref": "#/$defs/" + g.define(t)}
+	}
+	return schema{}
+}
+
+// agrega el struct a $defs una sola vez y devuelve su nombre
+func (g *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	g.names[t] = name
+	g.defs[name] = schema{} //reserva el nombre para los tipos recursivos
+
+	properties := make(schema)
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		key := parts[0]
+		if key == "" {
+			key = field.Name
+		}
+
+		properties[key] = g.schemaOf(field.Type)
+		if !strings.Contains(tag, ",omitempty") && field.Type.Kind() != reflect.Ptr {
+			required = append(required, key)
+		}
+	}
+
+	g.defs[name] = schema{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	return name
+}
+
+func sortedKeys(events map[string]interface{}) []string {
+	keys := make([]string, 0, len(events))
+	for key := range events {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
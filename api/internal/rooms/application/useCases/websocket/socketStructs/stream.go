@@ -0,0 +1,125 @@
+package socketStructs
+
+import (
+	"log"
+	optdom "suffgo/internal/options/domain"
+)
+
+// eventos que se pueden acumular para un consumidor lento antes de descartarlos
+const StreamBuffer = 64
+
+// consumidores de solo lectura (SSE): reciben los eventos que se envian a los clientes
+// sin ocupar lugar en la sala ni contar para el quorum
+type streamList map[chan Event]struct{}
+
+// registra un consumidor y le envia la lista de clientes y la propuesta en curso.
+// El canal se cierra cuando la sala se vacia, la funcion devuelta lo da de baja
+func (r *RoomLobby) Subscribe() (<-chan Event, func()) {
+	events := make(chan Event, StreamBuffer)
+	proposal := r.currentProposalEvent()
+
+	r.clientsmx.RLock()
+	r.streamsmx.Lock()
+	if r.streamsClosed {
+		close(events)
+	} else {
+		if r.streams == nil {
+			r.streams = make(streamList)
+		}
+		r.streams[events] = struct{}{}
+		events <- r.clientListEvent()
+		if proposal != nil {
+			events <- Event{Action: EventNextProp, Payload: marshalOrPanic(proposal)}
+		}
+		if r.isPaused() {
+			events <- r.pauseEvent("")
+		}
+	}
+	r.streamsmx.Unlock()
+	r.clientsmx.RUnlock()
+
+	return events, func() { r.dropStream(events) }
+}
+
+func (r *RoomLobby) dropStream(events chan Event) {
+	r.clientsmx.RLock()
+	r.streamsmx.Lock()
+	if _, ok := r.streams[events]; !ok {
+		r.streamsmx.Unlock()
+		r.clientsmx.RUnlock()
+		return
+	}
+	delete(r.streams, events)
+	close(events)
+
+	//replica abierta solo para consumidores de lectura: se descarta con el ultimo
+	empty := len(r.streams) == 0 && len(r.clients) == 0 && len(r.waiting) == 0
+	if empty {
+		r.streamsClosed = true
+	}
+	r.streamsmx.Unlock()
+	r.clientsmx.RUnlock()
+
+	if empty {
+		r.votingmx.Lock()
+		r.stopTimerLocked()
+		r.votingmx.Unlock()
+		r.leaveBus()
+		r.Empty <- struct{}{}
+	}
+}
+
+// envia el evento a los consumidores sin bloquear la sala, si el buffer esta lleno se descarta
+func (r *RoomLobby) stream(event Event) {
+	r.streamsmx.Lock()
+	defer r.streamsmx.Unlock()
+
+	//el id y la version son propios de cada conexion websocket
+	event.ID = ""
+	event.Version = 0
+	for events := range r.streams {
+		select {
+		case events <- event:
+		default:
+			log.Printf("stream of room id = %d is full, dropping %s \n", r.room.ID().Id, event.Action)
+		}
+	}
+}
+
+// se llama antes de descartar la sala, los consumidores vuelven a conectarse a la nueva
+func (r *RoomLobby) closeStreams() {
+	r.streamsmx.Lock()
+	defer r.streamsmx.Unlock()
+
+	for events := range r.streams {
+		close(events)
+	}
+	r.streams = nil
+	r.streamsClosed = true
+}
+
+// propuesta en curso con sus opciones, nil si la votacion no empezo
+func (r *RoomLobby) currentProposalEvent() *ProposalEvent {
+	proposal := r.currentProposal()
+	if proposal == nil {
+		return nil
+	}
+
+	options, err := r.optRepo.GetByProposal(proposal.ID())
+	if err != nil {
+		log.Println(err.Error())
+	}
+
+	var optionsValue []optdom.OptionDTO
+	for _, option := range options {
+		optionsValue = append(optionsValue, optdom.OptionDTO{
+			ID:         option.ID().Id,
+			Value:      option.Value().Value,
+			ProposalID: option.ProposalID().Id,
+		})
+	}
+
+	lastProp := len(r.proposals) > 0 && r.proposals[len(r.proposals)-1].ID().Id == proposal.ID().Id
+	proposalEvt := r.proposalEvent(*proposal, optionsValue, lastProp)
+	return &proposalEvt
+}
@@ -0,0 +1,107 @@
+package socketStructs
+
+import "log"
+
+// cantidad de participantes conectados que ocupan un lugar en la sala, en todas las instancias.
+// Los observadores no ocupan lugar
+func (r *RoomLobby) VoterCount() int {
+	r.clientsmx.RLock()
+	defer r.clientsmx.RUnlock()
+	return r.voterCount()
+}
+
+// debe llamarse con clientsmx tomado
+func (r *RoomLobby) voterCount() int {
+	count := 0
+	for client := range r.clients {
+		if !r.IsObserver(client.User.ID().Id) {
+			count++
+		}
+	}
+	for _, remotes := range r.remote {
+		for id := range remotes {
+			if !r.IsObserver(id) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// true si la sala alcanzo su VoterLimit (0 = sin limite)
+func (r *RoomLobby) IsFull() bool {
+	if r.settings == nil {
+		return false
+	}
+
+	limit := r.settings.VoterLimit().VoterLimit
+	return limit > 0 && r.VoterCount() >= limit
+}
+
+func (r *RoomLobby) WaitingListEnabled() bool {
+	return r.settings != nil && r.settings.WaitingList().Enabled()
+}
+
+// agrega al cliente al final de la lista de espera
+func (r *RoomLobby) Enqueue(client *Client) {
+	r.prepareClient(client)
+
+	r.clientsmx.Lock()
+	defer r.clientsmx.Unlock()
+
+	r.waiting = append(r.waiting, client)
+	log.Printf("user %s queued in room id = %d, position %d \n", client.User.Username().Username, r.room.ID().Id, len(r.waiting))
+
+	client.egress <- Event{
+		Action:  EventWaitingList,
+		Payload: marshalOrPanic(WaitingListEvent{Position: len(r.waiting)}),
+	}
+}
+
+// saca al cliente de la cola, debe llamarse con clientsmx tomado
+func (r *RoomLobby) dequeue(client *Client) bool {
+	for i, queued := range r.waiting {
+		if queued == client {
+			r.waiting = append(r.waiting[:i], r.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// admite en orden a los usuarios en espera mientras haya lugar, debe llamarse con clientsmx tomado
+func (r *RoomLobby) admitFromQueue() {
+	if len(r.waiting) == 0 || r.settings == nil {
+		return
+	}
+
+	limit := r.settings.VoterLimit().VoterLimit
+	admitted := false
+	for len(r.waiting) > 0 && (limit <= 0 || r.voterCount() < limit) {
+		next := r.waiting[0]
+		r.waiting = r.waiting[1:]
+
+		admitted = true
+		log.Printf("user %s admitted from waiting list \n", next.User.Username().Username)
+
+		next.egress <- Event{
+			Action:  EventWaitingList,
+			Payload: marshalOrPanic(WaitingListEvent{Admitted: true}),
+		}
+		r.admit(next)
+	}
+
+	if admitted {
+		r.notifyQueuePositions()
+	}
+}
+
+// debe llamarse con clientsmx tomado
+func (r *RoomLobby) notifyQueuePositions() {
+	for i, queued := range r.waiting {
+		queued.egress <- Event{
+			Action:  EventWaitingList,
+			Payload: marshalOrPanic(WaitingListEvent{Position: i + 1}),
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// mensaje del chat de la sala en vivo, el remitente lo asigna el servidor
+type ChatMessage struct {
+	ID       uint
+	RoomID   uint
+	UserID   uint
+	Username string
+	Message  string
+	SentAt   time.Time
+}
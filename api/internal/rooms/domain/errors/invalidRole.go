@@ -0,0 +1,9 @@
+package errors
+
+type invalidRole string
+
+const ErrInvalidRole invalidRole = "invalid role, expected co_admin, voter or observer."
+
+func (r invalidRole) Error() string {
+	return string(r)
+}
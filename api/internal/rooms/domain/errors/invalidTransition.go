@@ -0,0 +1,9 @@
+package errors
+
+type invalidTransitionConst string
+
+const ErrInvalidTransition invalidTransitionConst = "invalid room state transition."
+
+func (r invalidTransitionConst) Error() string {
+	return string(r)
+}
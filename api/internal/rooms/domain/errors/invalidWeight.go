@@ -0,0 +1,9 @@
+package errors
+
+type invalidWeight string
+
+const ErrInvalidWeight invalidWeight = "voting weight must be greater than zero."
+
+func (w invalidWeight) Error() string {
+	return string(w)
+}
@@ -0,0 +1,9 @@
+package errors
+
+type messageNotFoundConst string
+
+const ErrMessageNotFound messageNotFoundConst = "message not found."
+
+func (m messageNotFoundConst) Error() string {
+	return string(m)
+}
@@ -0,0 +1,9 @@
+package errors
+
+type roomNotLiveConst string
+
+const ErrRoomNotLive roomNotLiveConst = "room is not live."
+
+func (r roomNotLiveConst) Error() string {
+	return string(r)
+}
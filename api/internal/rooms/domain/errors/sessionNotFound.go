@@ -0,0 +1,9 @@
+package errors
+
+type sessionNotFoundConst string
+
+const ErrSessionNotFound sessionNotFoundConst = "voting session not found."
+
+func (r sessionNotFoundConst) Error() string {
+	return string(r)
+}
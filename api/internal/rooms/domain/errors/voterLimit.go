@@ -0,0 +1,9 @@
+package errors
+
+type voterLimitReached string
+
+const ErrVoterLimit voterLimitReached = "room voter limit reached."
+
+func (r voterLimitReached) Error() string {
+	return string(r)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"encoding/json"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+type (
+	// mensaje que replica el estado de una sala entre las instancias de la api
+	BusMessage struct {
+		RoomID  uint            `json:"room_id"`
+		Origin  string          `json:"origin"` //instancia que publico el mensaje
+		Kind    string          `json:"kind"`
+		Payload json.RawMessage `json:"payload"`
+	}
+
+	// pub/sub entre instancias que atienden la misma sala
+	LobbyBus interface {
+		// id de esta instancia, se usa para descartar los mensajes propios
+		InstanceID() string
+		Publish(msg BusMessage) error
+		// el handler recibe todos los mensajes de la sala, incluidos los propios. Devuelve la funcion para desuscribirse
+		Subscribe(roomID sv.ID, handler func(BusMessage)) (func(), error)
+	}
+)
+
+const (
+	// una instancia pide a las demas el estado de la sala
+	BusHello = "hello"
+	// lo entrega el bus a los suscriptores locales cuando pudo perder mensajes, por ejemplo al reconectarse
+	BusResync = "resync"
+)
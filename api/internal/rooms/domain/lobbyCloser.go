@@ -0,0 +1,10 @@
+package domain
+
+import (
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+// cierra la sala en vivo en todas las instancias y la marca como finished
+type LobbyCloser interface {
+	CloseLobby(roomID sv.ID, reason string)
+}
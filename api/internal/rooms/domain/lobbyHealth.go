@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+type (
+	// estado de las conexiones de una sala en vivo
+	LobbyHealth struct {
+		RoomID       uint           `json:"room_id"`
+		Connected    int            `json:"connected"`
+		Reconnecting int            `json:"reconnecting"` //en periodo de reconexion
+		Queued       int            `json:"queued"`       //en lista de espera
+		Remote       int            `json:"remote"`       //conectados a otras instancias
+		Reaped       int64          `json:"reaped"`       //conexiones cerradas por no responder el ping
+		AvgLatencyMs int64          `json:"avg_latency_ms"`
+		Clients      []ClientHealth `json:"clients"`
+	}
+
+	ClientHealth struct {
+		UserID    uint       `json:"user_id"`
+		Connected bool       `json:"connected"`
+		LatencyMs int64      `json:"latency_ms"` //ultimo ping/pong
+		LastPong  *time.Time `json:"last_pong"`
+	}
+)
@@ -0,0 +1,11 @@
+package domain
+
+import (
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+// informa la ocupacion de las salas en vivo (websocket)
+type LobbyOccupancy interface {
+	ConnectedVoters(roomID sv.ID) int
+	IsConnected(roomID sv.ID, userID sv.ID) bool
+}
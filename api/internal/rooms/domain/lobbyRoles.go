@@ -0,0 +1,10 @@
+package domain
+
+import (
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+// avisa a la sala en vivo que cambiaron los roles o el dueño
+type LobbyRoles interface {
+	RolesChanged(roomID sv.ID)
+}
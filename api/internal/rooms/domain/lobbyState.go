@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// progreso de la sala en vivo que se persiste para recuperarlo si se reinicia el servidor
+type LobbyState struct {
+	NextProposal   int        //indice de la proxima propuesta a abrir
+	VotingOpen     bool       //la votacion de VotingProposal sigue abierta
+	VotingProposal uint       //propuesta que se esta votando (o la ultima votada)
+	ClosesAt       *time.Time //fin de la cuenta regresiva, nil si la propuesta no tiene tiempo o la sala esta pausada
+	Remaining      int        //segundos que quedaban al pausar la sala, 0 si no hay limite
+	Round          int        //ronda de VotingProposal, mayor a 1 en las re-votaciones
+}
@@ -64,12 +64,44 @@ type (
 	AddSingleUserRequest struct {
 		UserData string `json:"user_data"`
 		RoomID   uint   `json:"room_id"`
+		Weight   *int   `json:"weight"` //peso del voto, 1 por defecto
+	}
+
+	UpdateWeightRequest struct {
+		UserId uint `json:"user_id"`
+		RoomId uint `json:"room_id"`
+		Weight int  `json:"weight"`
 	}
 
 	RemoveFromWhitelistRequest struct {
 		UserId uint `json:"user_id"`
 		RoomId uint `json:"room_id"`
 	}
+
+	UpdateRoleRequest struct {
+		UserId uint   `json:"user_id"`
+		RoomId uint   `json:"room_id"`
+		Role   string `json:"role"` //co_admin, voter u observer
+	}
+
+	TransferOwnershipRequest struct {
+		UserId uint `json:"user_id"` //nuevo dueño
+		RoomId uint `json:"room_id"`
+	}
+
+	RoomRoleDTO struct {
+		UserID uint   `json:"user_id"`
+		Role   string `json:"role"`
+	}
+
+	SetOfficialSessionRequest struct {
+		SessionID uint `json:"session_id"`
+	}
+
+	ChangeStateRequest struct {
+		State  string `json:"state"` //cancelled o archived
+		Reason string `json:"reason"`
+	}
 )
 
 func NewRoom(
@@ -118,6 +150,10 @@ func (r *Room) AdminID() sv.ID {
 	return *r.adminID
 }
 
+func (r *Room) SetAdminID(adminID sv.ID) {
+	r.adminID = &adminID
+}
+
 func (r *Room) Code() v.InviteCode {
 	return *r.code
 }
@@ -138,6 +174,26 @@ func (r *Room) State() *v.State {
 	return r.state
 }
 
+// valida y aplica el cambio de estado, devuelve el registro para el historial.
+// actorID es nil si el cambio lo hace el sistema
+func (r *Room) ChangeState(to string, actorID *sv.ID, reason string) (*StateTransition, error) {
+	from := r.state.CurrentState
+	if err := r.state.SetState(to); err != nil {
+		return nil, err
+	}
+
+	transition := &StateTransition{
+		RoomID: r.ID().Id,
+		From:   from,
+		To:     to,
+		Reason: reason,
+	}
+	if actorID != nil {
+		transition.ActorID = &actorID.Id
+	}
+	return transition, nil
+}
+
 func (r *Room) Image() *v.Image {
 	return r.image
 }
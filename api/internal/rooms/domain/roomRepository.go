@@ -12,10 +12,27 @@ type RoomRepository interface {
 	GetByAdminID(adminID sv.ID) ([]Room, error)
 	Restore(id sv.ID) error
 	GetRoomByCode(inviteCode string) (*Room, error)
-	AddToWhitelist(roomID sv.ID, userID sv.ID) error
+	AddToWhitelist(roomID sv.ID, userID sv.ID, weight int) error
 	UserInWhitelist(roomID sv.ID, userID sv.ID) (bool, error)
+	WhitelistWeight(roomID sv.ID, userID sv.ID) (int, error)
+	UpdateWhitelistWeight(roomID sv.ID, userID sv.ID, weight int) error
+	CountWhitelist(roomID sv.ID) (int, error)
+	WhitelistTotalWeight(roomID sv.ID) (int, error)
 	Update(room *Room) (*Room, error)
 	RemoveFromWhitelist(roomId sv.ID, userId sv.ID) error
-	RestartRoom(roomId sv.ID) error
 	HistoryRooms(userId sv.ID) ([]Room, error)
+	SaveLobbyState(roomId sv.ID, state LobbyState) error
+	GetLobbyState(roomId sv.ID) (*LobbyState, error) //nil si la sala no tiene progreso guardado
+	GetRoles(roomId sv.ID) (map[uint]string, error)  //solo co_admin y observer, el resto es voter
+	SetRole(roomId sv.ID, userId sv.ID, role string) error
+	TransferOwnership(roomId sv.ID, newOwnerId sv.ID) error
+	SaveChatMessage(message ChatMessage) (*ChatMessage, error)
+	GetChatMessages(roomId sv.ID, limit int) ([]ChatMessage, error) //los ultimos limit mensajes, del mas viejo al mas nuevo
+	DeleteChatMessage(roomId sv.ID, messageId sv.ID, deletedBy sv.ID) error
+	ChangeState(transition StateTransition) error //falla si la sala ya no esta en transition.From
+	GetTimeline(roomId sv.ID) ([]StateTransition, error)
+	ActiveSession(roomId sv.ID) (*VotingSession, error) //crea la primera sesion si la sala no tiene
+	NewSession(roomId sv.ID) (*VotingSession, error)    //archiva la sesion activa y abre la siguiente
+	GetSessions(roomId sv.ID) ([]VotingSession, error)
+	SetOfficialSession(roomId sv.ID, sessionId sv.ID) error
 }
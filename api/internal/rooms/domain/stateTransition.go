@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// cambio de estado de la sala, forma el historial que se muestra en el timeline
+type StateTransition struct {
+	ID        uint      `json:"id"`
+	RoomID    uint      `json:"room_id"`
+	From      string    `json:"from"` //vacio en la creacion de la sala
+	To        string    `json:"to"`
+	ActorID   *uint     `json:"actor_id"` //nil si el cambio lo hizo el sistema
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package valueobjects
+
+import "errors"
+
+const (
+	RoleOwner    = "owner"    //dueño de la sala (admin_id), hay uno solo
+	RoleCoAdmin  = "co_admin" //puede manejar la votacion en vivo
+	RoleVoter    = "voter"    //rol por defecto
+	RoleObserver = "observer" //solo mira, no vota
+)
+
+type Role struct {
+	Role string
+}
+
+func NewRole(role string) (*Role, error) {
+	if role == "" {
+		role = RoleVoter
+	}
+
+	switch role {
+	case RoleOwner, RoleCoAdmin, RoleVoter, RoleObserver:
+		return &Role{Role: role}, nil
+	}
+
+	return nil, errors.New("invalid role")
+}
+
+// owner y co_admin pueden abrir propuestas, mostrar resultados y expulsar usuarios
+func (r Role) CanModerate() bool {
+	return r.Role == RoleOwner || r.Role == RoleCoAdmin
+}
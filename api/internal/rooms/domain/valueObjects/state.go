@@ -1,35 +1,83 @@
 package valueobjects
 
-import "errors"
+import (
+	"errors"
+	"fmt"
 
-type State struct{
+	roomerr "suffgo/internal/rooms/domain/errors"
+)
+
+const (
+	StateCreated    = "created"
+	StateScheduled  = "scheduled" //tiene horario de inicio, la abre el scheduler
+	StateOnline     = "online"    //abierta, los usuarios pueden entrar
+	StateInProgress = "in progress"
+	StatePaused     = "paused"
+	StateFinished   = "finished"
+	StateCancelled  = "cancelled" //se cerro sin que empezara la votacion
+	StateArchived   = "archived"
+)
+
+// estados a los que se puede pasar desde cada estado. Online e in progress vuelven a
+// created cuando todos se van de la sala, finished vuelve a in progress si se re-vota la ultima propuesta
+var transitions = map[string][]string{
+	StateCreated:    {StateScheduled, StateOnline, StateCancelled},
+	StateScheduled:  {StateCreated, StateOnline, StateCancelled},
+	StateOnline:     {StateCreated, StateInProgress, StateCancelled},
+	StateInProgress: {StateCreated, StatePaused, StateFinished},
+	StatePaused:     {StateCreated, StateInProgress, StateFinished},
+	StateFinished:   {StateInProgress, StateArchived},
+	StateCancelled:  {StateArchived},
+	StateArchived:   {},
+}
+
+type State struct {
 	CurrentState string
 }
 
 func NewState(value string) (*State, error) {
-	states := getStates()
-	for _, state := range states {
-		if state == value {
-			return &State{
-				CurrentState: state,
-			}, nil
-		}
+	if _, ok := transitions[value]; !ok {
+		return nil, errors.ErrUnsupported
 	}
 
-	return nil, errors.ErrUnsupported
+	return &State{
+		CurrentState: value,
+	}, nil
 }
 
+// cambia el estado solo si la transicion esta permitida
 func (s *State) SetState(value string) error {
-	states := getStates()
-	for _, state := range states {
+	if !s.CanTransition(value) {
+		return fmt.Errorf("%w: %q to %q", roomerr.ErrInvalidTransition, s.CurrentState, value)
+	}
+
+	s.CurrentState = value
+	return nil
+}
+
+func (s *State) CanTransition(value string) bool {
+	for _, state := range transitions[s.CurrentState] {
 		if state == value {
-			s.CurrentState = value
+			return true
 		}
 	}
+	return false
+}
 
-	return errors.ErrUnsupported
+// la sala todavia se puede configurar
+func (s *State) NotStarted() bool {
+	return s.CurrentState == StateCreated || s.CurrentState == StateScheduled
 }
 
-func getStates() [4]string{
-	return [...]string{"created", "online", "in progress", "finished"}
-}
\ No newline at end of file
+// estado al cerrar la sala: finished si la votacion empezo, cancelled si no
+func (s *State) ClosedState() string {
+	if s.CurrentState == StateInProgress || s.CurrentState == StatePaused {
+		return StateFinished
+	}
+	return StateCancelled
+}
+
+// la sala tiene una instancia en vivo
+func (s *State) Live() bool {
+	return s.CurrentState == StateOnline || s.CurrentState == StateInProgress || s.CurrentState == StatePaused
+}
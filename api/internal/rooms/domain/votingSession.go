@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// intento de votacion de la sala. Reiniciar la sala abre una sesion nueva y archiva la anterior,
+// los votos quedan guardados en la sesion en la que se emitieron
+type VotingSession struct {
+	ID         uint       `json:"id"`
+	RoomID     uint       `json:"room_id"`
+	Number     int        `json:"number"`   //1 para la primera sesion de la sala
+	Active     bool       `json:"active"`   //recibe los votos de la sala en vivo, hay una sola por sala
+	Official   bool       `json:"official"` //se usa para los resultados, hay una sola por sala
+	StartedAt  time.Time  `json:"started_at"`
+	ArchivedAt *time.Time `json:"archived_at"` //nil mientras es la sesion activa
+}
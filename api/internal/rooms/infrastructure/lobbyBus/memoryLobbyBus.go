@@ -0,0 +1,98 @@
+package lobbybus
+
+import (
+	"sync"
+
+	d "suffgo/internal/rooms/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+
+	"github.com/google/uuid"
+)
+
+// bus en memoria para una sola instancia: todos los mensajes son propios y los lobbies los descartan
+type MemoryLobbyBus struct {
+	mx          sync.RWMutex
+	instanceID  string
+	nextID      int
+	subscribers map[uint]map[int]func(d.BusMessage)
+}
+
+func NewMemoryLobbyBus() *MemoryLobbyBus {
+	return &MemoryLobbyBus{
+		instanceID:  uuid.New().String(),
+		subscribers: make(map[uint]map[int]func(d.BusMessage)),
+	}
+}
+
+func (b *MemoryLobbyBus) InstanceID() string {
+	return b.instanceID
+}
+
+func (b *MemoryLobbyBus) Publish(msg d.BusMessage) error {
+	if msg.Origin == "" {
+		msg.Origin = b.instanceID
+	}
+
+	b.mx.RLock()
+	defer b.mx.RUnlock()
+
+	//quien publica puede tener tomados los locks del lobby
+	for _, handler := range b.subscribers[msg.RoomID] {
+		go handler(msg)
+	}
+	return nil
+}
+
+// entrega el mensaje en orden, para los mensajes que llegan de otras instancias
+func (b *MemoryLobbyBus) deliver(msg d.BusMessage) {
+	b.mx.RLock()
+	handlers := make([]func(d.BusMessage), 0, len(b.subscribers[msg.RoomID]))
+	for _, handler := range b.subscribers[msg.RoomID] {
+		handlers = append(handlers, handler)
+	}
+	b.mx.RUnlock()
+
+	for _, handler := range handlers {
+		handler(msg)
+	}
+}
+
+func (b *MemoryLobbyBus) subscribed(roomID uint) bool {
+	b.mx.RLock()
+	defer b.mx.RUnlock()
+	return len(b.subscribers[roomID]) > 0
+}
+
+// salas con suscriptores en esta instancia
+func (b *MemoryLobbyBus) rooms() []uint {
+	b.mx.RLock()
+	defer b.mx.RUnlock()
+
+	rooms := make([]uint, 0, len(b.subscribers))
+	for roomID := range b.subscribers {
+		rooms = append(rooms, roomID)
+	}
+	return rooms
+}
+
+func (b *MemoryLobbyBus) Subscribe(roomID sv.ID, handler func(d.BusMessage)) (func(), error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.subscribers[roomID.Id] == nil {
+		b.subscribers[roomID.Id] = make(map[int]func(d.BusMessage))
+	}
+	id := b.nextID
+	b.nextID++
+	b.subscribers[roomID.Id][id] = handler
+
+	return func() {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		delete(b.subscribers[roomID.Id], id)
+		if len(b.subscribers[roomID.Id]) == 0 {
+			delete(b.subscribers, roomID.Id)
+		}
+	}, nil
+}
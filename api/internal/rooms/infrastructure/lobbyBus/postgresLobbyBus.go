@@ -0,0 +1,216 @@
+package lobbybus
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"suffgo/cmd/database"
+	d "suffgo/internal/rooms/domain"
+	m "suffgo/internal/rooms/infrastructure/models"
+	sv "suffgo/internal/shared/domain/valueObjects"
+
+	"github.com/lib/pq"
+)
+
+const (
+	lobbyBusChannel = "lobby_bus"
+	//postgres limita el payload de NOTIFY a 8000 bytes
+	maxNotifyPayload = 7900
+	//los mensajes grandes se leen apenas llegan, despues se pueden borrar
+	payloadTTL = 5 * time.Minute
+)
+
+var ErrBusPayloadNotFound = errors.New("lobby bus payload not found")
+
+// notificacion de un mensaje guardado en lobby_bus_payload. Los mensajes comunes tambien tienen room_id
+type payloadRef struct {
+	Ref    uint `json:"payload_ref"`
+	RoomID uint `json:"room_id"`
+}
+
+// notificaciones de una sala pendientes de entregar, en el orden en que llegaron
+type roomQueue struct {
+	pending []string
+	running bool
+}
+
+// bus entre instancias sobre LISTEN/NOTIFY de postgres. Todas las salas comparten un canal y
+// cada mensaje se despacha a los suscriptores de su sala
+type PostgresLobbyBus struct {
+	db       database.Database
+	listener *pq.Listener
+	memory   *MemoryLobbyBus //registro local de suscriptores
+
+	queuesmx sync.Mutex
+	queues   map[uint]*roomQueue
+}
+
+func NewPostgresLobbyBus(db database.Database, dsn string) (*PostgresLobbyBus, error) {
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("lobby bus listener: %v \n", err)
+		}
+	})
+
+	if err := listener.Listen(lobbyBusChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	b := &PostgresLobbyBus{
+		db:       db,
+		listener: listener,
+		memory:   NewMemoryLobbyBus(),
+		queues:   make(map[uint]*roomQueue),
+	}
+
+	go b.listen()
+
+	return b, nil
+}
+
+func (b *PostgresLobbyBus) InstanceID() string {
+	return b.memory.InstanceID()
+}
+
+func (b *PostgresLobbyBus) Publish(msg d.BusMessage) error {
+	if msg.Origin == "" {
+		msg.Origin = b.InstanceID()
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	//el mensaje se guarda en la base y se notifica solo su id
+	if len(payload) > maxNotifyPayload {
+		stored := &m.LobbyBusPayload{Payload: string(payload)}
+		if _, err := b.db.GetDb().Insert(stored); err != nil {
+			return err
+		}
+		b.db.GetDb().Where("created_at < ?", time.Now().Add(-payloadTTL)).Delete(&m.LobbyBusPayload{})
+
+		payload, _ = json.Marshal(payloadRef{Ref: stored.ID, RoomID: msg.RoomID})
+	}
+
+	_, err = b.db.GetDb().Exec("SELECT pg_notify(?, ?)", lobbyBusChannel, string(payload))
+	return err
+}
+
+func (b *PostgresLobbyBus) Subscribe(roomID sv.ID, handler func(d.BusMessage)) (func(), error) {
+	return b.memory.Subscribe(roomID, handler)
+}
+
+func (b *PostgresLobbyBus) listen() {
+	for {
+		select {
+		case notification, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			//nil despues de una reconexion: las salas vuelven a pedir el estado que se pudo perder
+			if notification == nil {
+				b.resync()
+				continue
+			}
+
+			var ref payloadRef
+			if err := json.Unmarshal([]byte(notification.Extra), &ref); err != nil {
+				log.Printf("lobby bus: invalid message: %v \n", err)
+				continue
+			}
+			b.dispatch(ref.RoomID, notification.Extra)
+
+		case <-time.After(90 * time.Second):
+			//mantiene viva la conexion del listener
+			go b.listener.Ping()
+		}
+	}
+}
+
+// encola la notificacion en su sala. Cada sala se entrega en su propia goroutine para que un cliente
+// lento o una expulsion que espera al cliente no frenen los mensajes de las demas salas
+func (b *PostgresLobbyBus) dispatch(roomID uint, extra string) {
+	b.queuesmx.Lock()
+	defer b.queuesmx.Unlock()
+
+	queue := b.queues[roomID]
+	if queue == nil {
+		queue = &roomQueue{}
+		b.queues[roomID] = queue
+	}
+	queue.pending = append(queue.pending, extra)
+	if !queue.running {
+		queue.running = true
+		go b.drain(roomID, queue)
+	}
+}
+
+// entrega las notificaciones de la sala en orden hasta vaciar la cola
+func (b *PostgresLobbyBus) drain(roomID uint, queue *roomQueue) {
+	for {
+		b.queuesmx.Lock()
+		if len(queue.pending) == 0 {
+			queue.running = false
+			delete(b.queues, roomID)
+			b.queuesmx.Unlock()
+			return
+		}
+		extra := queue.pending[0]
+		queue.pending = queue.pending[1:]
+		b.queuesmx.Unlock()
+
+		//sin suscriptores no hace falta leer los mensajes guardados en la base
+		if !b.memory.subscribed(roomID) {
+			continue
+		}
+
+		msg, err := b.load(extra)
+		if err != nil {
+			log.Printf("lobby bus: invalid message: %v \n", err)
+			continue
+		}
+		b.memory.deliver(*msg)
+	}
+}
+
+// arma el mensaje de la notificacion, si es una referencia lo lee de la base
+func (b *PostgresLobbyBus) load(extra string) (*d.BusMessage, error) {
+	var ref payloadRef
+	if err := json.Unmarshal([]byte(extra), &ref); err != nil {
+		return nil, err
+	}
+
+	if ref.Ref != 0 {
+		stored := &m.LobbyBusPayload{}
+		found, err := b.db.GetDb().ID(ref.Ref).Get(stored)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, ErrBusPayloadNotFound
+		}
+		extra = stored.Payload
+	}
+
+	var msg d.BusMessage
+	if err := json.Unmarshal([]byte(extra), &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (b *PostgresLobbyBus) resync() {
+	for _, roomID := range b.memory.rooms() {
+		resync, _ := json.Marshal(d.BusMessage{RoomID: roomID, Kind: d.BusResync})
+		b.dispatch(roomID, string(resync))
+	}
+}
+
+func (b *PostgresLobbyBus) Close() error {
+	return b.listener.Close()
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// mensajes del chat de la sala, los borrados por un moderador quedan con deleted_at
+type ChatMessage struct {
+	ID        uint       `xorm:"'id' pk autoincr"`
+	RoomID    uint       `xorm:"'room_id' not null index"`
+	UserID    uint       `xorm:"'user_id' not null"`
+	Username  string     `xorm:"'username' varchar(255) not null"`
+	Message   string     `xorm:"'message' text not null"`
+	SentAt    time.Time  `xorm:"'sent_at' created"`
+	DeletedBy *uint      `xorm:"'deleted_by' null"`
+	DeletedAt *time.Time `xorm:"deleted"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// mensaje del bus entre instancias que no entra en un NOTIFY: se guarda aca y se notifica su id
+type LobbyBusPayload struct {
+	ID        uint      `xorm:"'id' pk autoincr"`
+	Payload   string    `xorm:"'payload' text not null"`
+	CreatedAt time.Time `xorm:"'created_at' created index"`
+}
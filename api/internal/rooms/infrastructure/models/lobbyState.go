@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// progreso de la sala en vivo, una fila por sala mientras esta online, in progress o paused
+type LobbyState struct {
+	RoomID         uint       `xorm:"'room_id' pk"`
+	NextProposal   int        `xorm:"'next_proposal' not null default 0"`
+	VotingOpen     bool       `xorm:"'voting_open' not null default false"`
+	VotingProposal uint       `xorm:"'voting_proposal' not null default 0"`
+	ClosesAt       *time.Time `xorm:"'closes_at' null"`
+	Remaining      int        `xorm:"'remaining' not null default 0"`
+	Round          int        `xorm:"'round' not null default 1"`
+	UpdatedAt      time.Time  `xorm:"'updated_at' updated"`
+}
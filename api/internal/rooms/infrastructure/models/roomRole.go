@@ -0,0 +1,9 @@
+package models
+
+// rol del usuario en la sala, los usuarios sin registro son voter. El dueño es admin_id de room
+type RoomRole struct {
+	ID     uint   `xorm:"'id' pk autoincr"`
+	RoomID uint   `xorm:"'room_id' not null unique(room_user_idx)"`
+	UserID uint   `xorm:"'user_id' not null unique(room_user_idx)"`
+	Role   string `xorm:"'role' varchar(16) not null"`
+}
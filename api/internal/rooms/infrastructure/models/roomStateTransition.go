@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// historial de cambios de estado de la sala
+type RoomStateTransition struct {
+	ID        uint      `xorm:"'id' pk autoincr"`
+	RoomID    uint      `xorm:"'room_id' not null index"`
+	FromState string    `xorm:"'from_state' varchar(16) not null"`
+	ToState   string    `xorm:"'to_state' varchar(16) not null"`
+	ActorID   *uint     `xorm:"'actor_id' null"`
+	Reason    string    `xorm:"'reason' varchar(255) not null"`
+	CreatedAt time.Time `xorm:"'created_at' created"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// sesiones de votacion de la sala, una sola activa y una sola oficial por sala
+type VotingSession struct {
+	ID         uint       `xorm:"'id' pk autoincr"`
+	RoomID     uint       `xorm:"'room_id' not null unique(room_number_idx)"`
+	Number     int        `xorm:"'number' not null unique(room_number_idx)"`
+	Active     bool       `xorm:"'active' not null default false"`
+	Official   bool       `xorm:"'official' not null default false"`
+	StartedAt  time.Time  `xorm:"'started_at' created"`
+	ArchivedAt *time.Time `xorm:"'archived_at' null"`
+}
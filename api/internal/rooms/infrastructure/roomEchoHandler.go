@@ -1,13 +1,17 @@
 package infrastructure
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 	r "suffgo/internal/rooms/application/useCases"
 	addUsers "suffgo/internal/rooms/application/useCases/addUsers"
 	roomWs "suffgo/internal/rooms/application/useCases/websocket"
+	"suffgo/internal/rooms/application/useCases/websocket/socketStructs"
 
 	d "suffgo/internal/rooms/domain"
 	v "suffgo/internal/rooms/domain/valueObjects"
@@ -42,6 +46,14 @@ type RoomEchoHandler struct {
 	ManageWsUsecase      *roomWs.ManageWsUsecase
 	WhiteListRmUsecase   *r.WhitelistRmUsecase
 	HistoryRoomsUsecase  *r.HistoryRooms
+	UpdateWeightUsecase  *r.UpdateWeightUsecase
+	UpdateRoleUsecase    *r.UpdateRoleUsecase
+	TransferUsecase      *r.TransferOwnershipUsecase
+	GetRolesUsecase      *r.GetRolesUsecase
+	GetTimelineUsecase   *r.GetTimelineUsecase
+	ChangeStateUsecase   *r.ChangeStateUsecase
+	GetSessionsUsecase   *r.GetSessionsUsecase
+	SetOfficialUsecase   *r.SetOfficialSessionUsecase
 }
 
 func NewRoomEchoHandler(
@@ -59,6 +71,14 @@ func NewRoomEchoHandler(
 	getSrByRoomIDUC *r.GetSrByRoomUsecase,
 	whitelistRmUC *r.WhitelistRmUsecase,
 	historyRoomsUC *r.HistoryRooms,
+	updateWeightUC *r.UpdateWeightUsecase,
+	updateRoleUC *r.UpdateRoleUsecase,
+	transferUC *r.TransferOwnershipUsecase,
+	getRolesUC *r.GetRolesUsecase,
+	getTimelineUC *r.GetTimelineUsecase,
+	changeStateUC *r.ChangeStateUsecase,
+	getSessionsUC *r.GetSessionsUsecase,
+	setOfficialUC *r.SetOfficialSessionUsecase,
 
 ) *RoomEchoHandler {
 	return &RoomEchoHandler{
@@ -76,6 +96,14 @@ func NewRoomEchoHandler(
 		GetSrByRoomIDUsecase: getSrByRoomIDUC,
 		WhiteListRmUsecase:   whitelistRmUC,
 		HistoryRoomsUsecase:  historyRoomsUC,
+		UpdateWeightUsecase:  updateWeightUC,
+		UpdateRoleUsecase:    updateRoleUC,
+		TransferUsecase:      transferUC,
+		GetRolesUsecase:      getRolesUC,
+		GetTimelineUsecase:   getTimelineUC,
+		ChangeStateUsecase:   changeStateUC,
+		GetSessionsUsecase:   getSessionsUC,
+		SetOfficialUsecase:   setOfficialUC,
 	}
 }
 
@@ -424,6 +452,9 @@ func (h *RoomEchoHandler) JoinRoom(c echo.Context) error {
 		if errors.Is(err, rerr.ErrRoomNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, rerr.ErrVoterLimit) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -509,7 +540,12 @@ func (h *RoomEchoHandler) AddSingleUser(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
 	}
 
-	err = h.AddSingleUserUsecase.Execute(req.UserData, *roomID, *userID)
+	weight := 1
+	if req.Weight != nil {
+		weight = *req.Weight
+	}
+
+	err = h.AddSingleUserUsecase.Execute(req.UserData, *roomID, *userID, weight)
 
 	if err != nil {
 
@@ -521,6 +557,8 @@ func (h *RoomEchoHandler) AddSingleUser(c echo.Context) error {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 		} else if errors.Is(err, rerr.ErrAlreadyInWhitelist) {
 			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrInvalidWeight) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		} else {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		}
@@ -686,6 +724,267 @@ func (r *RoomEchoHandler) RemoveFromWhitelistHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]interface{}{"success": "room updated successfully"})
 }
 
+func (r *RoomEchoHandler) UpdateWeightHandler(c echo.Context) error {
+
+	var req d.UpdateWeightRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	adminId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	roomId, err := sv.NewID(req.RoomId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	userId, err := sv.NewID(req.UserId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	err = r.UpdateWeightUsecase.Execute(*roomId, *userId, *adminId, req.Weight)
+
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrNotWhitelist) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrInvalidWeight) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		} else {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": "weight updated successfully"})
+}
+
+func (r *RoomEchoHandler) UpdateRoleHandler(c echo.Context) error {
+
+	var req d.UpdateRoleRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	ownerId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	roomId, err := sv.NewID(req.RoomId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	userId, err := sv.NewID(req.UserId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	err = r.UpdateRoleUsecase.Execute(*roomId, *userId, *ownerId, req.Role)
+
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) || errors.Is(err, uerr.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrInvalidRole) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		} else {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": "role updated successfully"})
+}
+
+func (r *RoomEchoHandler) TransferOwnershipHandler(c echo.Context) error {
+
+	var req d.TransferOwnershipRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	ownerId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	roomId, err := sv.NewID(req.RoomId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	newOwnerId, err := sv.NewID(req.UserId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	err = r.TransferUsecase.Execute(*roomId, *newOwnerId, *ownerId)
+
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) || errors.Is(err, uerr.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": "ownership transferred successfully"})
+}
+
+func (r *RoomEchoHandler) HealthHandler(c echo.Context) error {
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	userId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	health, err := r.ManageWsUsecase.Health(*roomId, *userId)
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, health)
+}
+
+func (r *RoomEchoHandler) GetRolesHandler(c echo.Context) error {
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	roles, err := r.GetRolesUsecase.Execute(*roomId)
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, roles)
+}
+
+func (r *RoomEchoHandler) TimelineHandler(c echo.Context) error {
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	timeline, err := r.GetTimelineUsecase.Execute(*roomId)
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, timeline)
+}
+
+func (r *RoomEchoHandler) ChangeStateHandler(c echo.Context) error {
+	var req d.ChangeStateRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	userId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	err = r.ChangeStateUsecase.Execute(*roomId, *userId, req.State, req.Reason)
+
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrInvalidTransition) || errors.Is(err, rerr.ErrStateConstraint) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		} else {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": "room state updated successfully"})
+}
+
+func (r *RoomEchoHandler) SessionsHandler(c echo.Context) error {
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	sessions, err := r.GetSessionsUsecase.Execute(*roomId)
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (r *RoomEchoHandler) SetOfficialSessionHandler(c echo.Context) error {
+	var req d.SetOfficialSessionRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	userId, err := GetUserIDFromSession(c)
+	if err != nil {
+		return err
+	}
+
+	err = r.SetOfficialUsecase.Execute(*roomId, *userId, sv.ID{Id: req.SessionID})
+
+	if err != nil {
+		if errors.Is(err, rerr.ErrRoomNotFound) || errors.Is(err, rerr.ErrSessionNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		} else if errors.Is(err, rerr.ErrUserNotAdmin) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		} else {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"success": "official session updated successfully"})
+}
+
 
 
 // En caso de devolver error lo hace en forma de response
@@ -724,6 +1023,70 @@ var (
 	}
 )
 
+// intervalo de los comentarios que mantienen abierta la conexion SSE detras de proxies
+const sseKeepAlive = 15 * time.Second
+
+// eventos de la sala en vivo para pantallas de solo lectura, los mismos que envia el websocket.
+// Si la sala no esta en vivo se indica al navegador que reintente
+func (h *RoomEchoHandler) StreamHandler(c echo.Context) error {
+	roomId, err := sv.NewID(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": se.ErrInvalidID.Error()})
+	}
+
+	events, unsubscribe, err := h.ManageWsUsecase.Stream(*roomId)
+	if err != nil && !errors.Is(err, rerr.ErrRoomNotLive) {
+		if errors.Is(err, rerr.ErrRoomNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if err != nil {
+		fmt.Fprintf(res, "retry: %d\nevent: room_offline\ndata: {\"error\":%q}\n\n", sseKeepAlive.Milliseconds(), err.Error())
+		res.Flush()
+		return nil
+	}
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	res.Flush()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-keepAlive.C:
+			fmt.Fprint(res, ": keep-alive\n\n")
+		case event, ok := <-events:
+			//la sala se cerro, el navegador se reconecta solo
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Action, data)
+		}
+		res.Flush()
+	}
+}
+
+// esquema de los eventos del websocket para los clientes
+func (h *RoomEchoHandler) WsSchemaHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, socketStructs.ProtocolSchema())
+}
+
 func (h *RoomEchoHandler) WsHandler(c echo.Context) error {
 
 	id := c.Param("room_id")
@@ -744,7 +1107,13 @@ func (h *RoomEchoHandler) WsHandler(c echo.Context) error {
 		return err
 	}
 
-	err = h.ManageWsUsecase.Execute(ws, *clientID, *roomId)
+	//token del evento session para retomar el lugar despues de un corte
+	resumeToken := c.QueryParam("resume")
+
+	//version del protocolo que pide el cliente, sin version se usa la v1
+	version, _ := strconv.Atoi(c.QueryParam("version"))
+
+	err = h.ManageWsUsecase.Execute(ws, *clientID, *roomId, resumeToken, version)
 	if err != nil {
 		ws.Close()
 		log.Println(err.Error())
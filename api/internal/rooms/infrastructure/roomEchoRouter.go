@@ -10,6 +10,7 @@ func InitializeRoomEchoRouter(e *echo.Echo, handler *RoomEchoHandler) {
 
 	roomGroup := e.Group("/v1/rooms")
 	roomGroup.GET("", handler.GetAllRooms)
+	roomGroup.GET("/ws/schema", handler.WsSchemaHandler)
 
 	roomGroup.Use(userInfr.AuthMiddleware)
 	roomGroup.POST("", handler.CreateRoom)
@@ -23,5 +24,15 @@ func InitializeRoomEchoRouter(e *echo.Echo, handler *RoomEchoHandler) {
 	roomGroup.GET("/ws/:room_id", handler.WsHandler)
 	roomGroup.PUT("/:id", handler.Update)
 	roomGroup.DELETE("/whitelist/removeUser", handler.RemoveFromWhitelistHandler)
+	roomGroup.PUT("/whitelist/weight", handler.UpdateWeightHandler)
+	roomGroup.GET("/:id/roles", handler.GetRolesHandler)
+	roomGroup.GET("/:id/health", handler.HealthHandler)
+	roomGroup.GET("/:id/stream", handler.StreamHandler)
+	roomGroup.GET("/:id/timeline", handler.TimelineHandler)
+	roomGroup.PUT("/:id/state", handler.ChangeStateHandler)
+	roomGroup.GET("/:id/sessions", handler.SessionsHandler)
+	roomGroup.PUT("/:id/sessions/official", handler.SetOfficialSessionHandler)
+	roomGroup.PUT("/roles", handler.UpdateRoleHandler)
+	roomGroup.PUT("/transfer", handler.TransferOwnershipHandler)
 	roomGroup.GET("/history", handler.History)
 }
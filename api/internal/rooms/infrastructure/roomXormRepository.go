@@ -2,16 +2,19 @@ package infrastructure
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"suffgo/cmd/database"
 	"suffgo/internal/rooms/domain"
 	d "suffgo/internal/rooms/domain"
 	re "suffgo/internal/rooms/domain/errors"
+	v "suffgo/internal/rooms/domain/valueObjects"
 	"suffgo/internal/rooms/infrastructure/mappers"
 	m "suffgo/internal/rooms/infrastructure/models"
 	se "suffgo/internal/shared/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
 	userRoomDom "suffgo/internal/userRooms/infrastructure/models"
+	"time"
 )
 
 type RoomXormRepository struct {
@@ -128,8 +131,32 @@ func (s *RoomXormRepository) Save(room d.Room) (*d.Room, error) {
 		roomModel.Image = room.Image().Image
 	}
 
-	_, err := s.db.GetDb().Insert(roomModel)
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err := session.Insert(roomModel); err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	//primer registro del historial de estados
+	adminID := roomModel.AdminID
+	_, err := session.Insert(&m.RoomStateTransition{
+		RoomID:  roomModel.ID,
+		ToState: roomModel.State,
+		ActorID: &adminID,
+		Reason:  "room created",
+	})
 	if err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	if err := session.Commit(); err != nil {
 		return nil, err
 	}
 
@@ -166,11 +193,12 @@ func (s *RoomXormRepository) GetRoomByCode(inviteCode string) (*d.Room, error) {
 }
 
 // agrego un registro a user_room (para usuario registrado)
-func (s *RoomXormRepository) AddToWhitelist(roomID sv.ID, userID sv.ID) error {
+func (s *RoomXormRepository) AddToWhitelist(roomID sv.ID, userID sv.ID, weight int) error {
 
 	reg := userRoomDom.UserRoom{
 		UserID: userID.Id,
 		RoomID: roomID.Id,
+		Weight: weight,
 	}
 
 	_, err := s.db.GetDb().Insert(&reg)
@@ -197,6 +225,62 @@ func (s *RoomXormRepository) UserInWhitelist(roomID sv.ID, userID sv.ID) (bool,
 	return true, nil
 }
 
+// peso del voto del usuario en la sala, 1 si no esta en la whitelist
+func (s *RoomXormRepository) WhitelistWeight(roomID sv.ID, userID sv.ID) (int, error) {
+	var reg userRoomDom.UserRoom
+	has, err := s.db.GetDb().Where("room_id = ? and user_id = ?", roomID.Id, userID.Id).Get(&reg)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !has || reg.Weight <= 0 {
+		return 1, nil
+	}
+
+	return reg.Weight, nil
+}
+
+func (s *RoomXormRepository) UpdateWhitelistWeight(roomID sv.ID, userID sv.ID, weight int) error {
+	affected, err := s.db.GetDb().Where("room_id = ? and user_id = ?", roomID.Id, userID.Id).Cols("weight").Update(&userRoomDom.UserRoom{Weight: weight})
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return re.ErrNotWhitelist
+	}
+
+	return nil
+}
+
+func (s *RoomXormRepository) CountWhitelist(roomID sv.ID) (int, error) {
+	count, err := s.db.GetDb().Where("room_id = ?", roomID.Id).Count(&userRoomDom.UserRoom{})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// suma de los pesos de la whitelist, los registros sin peso cuentan como 1
+func (s *RoomXormRepository) WhitelistTotalWeight(roomID sv.ID) (int, error) {
+	var total int64
+	_, err := s.db.GetDb().SQL(`
+		SELECT COALESCE(SUM(CASE WHEN weight > 0 THEN weight ELSE 1 END), 0)
+		FROM user_room
+		WHERE room_id = ?
+	`, roomID.Id).Get(&total)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return int(total), nil
+}
+
 func (r *RoomXormRepository) Update(room *d.Room) (*d.Room, error) {
 	roomID := room.ID().Id
 
@@ -211,8 +295,10 @@ func (r *RoomXormRepository) Update(room *d.Room) (*d.Room, error) {
 
 	updateRoom := mappers.DomainToModel(room)
 
+	//el estado solo cambia con ChangeState para que quede en el historial
 	affected, err := r.db.GetDb().
 		ID(roomID).
+		Omit("state").
 		Update(updateRoom)
 
 	if err != nil {
@@ -222,6 +308,7 @@ func (r *RoomXormRepository) Update(room *d.Room) (*d.Room, error) {
 		return nil, errors.New("no rows were updated")
 	}
 
+	updateRoom.State = existingRoom.State
 	updatedRoom, err := mappers.ModelToDomain(updateRoom)
 	if err != nil {
 		return nil, err
@@ -243,24 +330,257 @@ func (s *RoomXormRepository) RemoveFromWhitelist(roomId sv.ID, userId sv.ID) err
 	return nil
 }
 
-func (s *RoomXormRepository) RestartRoom(roomId sv.ID) error {
-	roomIDInt := int64(roomId.Value())
+// la sala vuelve a empezar desde la primera propuesta en una sesion nueva, los votos de la
+// sesion anterior se conservan. La sesion nueva pasa a ser la oficial si lo era la anterior
+func (s *RoomXormRepository) NewSession(roomId sv.ID) (*d.VotingSession, error) {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
 
-	_, err := s.db.GetDb().Exec(`
-		DELETE FROM vote
-		WHERE option_id IN (
-			SELECT o.id
-			FROM option o
-			JOIN proposal p ON o.proposal_id = p.id
-			WHERE p.room_id = ?
-		);
-	`, roomIDInt)
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
 
+	var last m.VotingSession
+	hasLast, err := session.Where("room_id = ?", roomId.Id).Desc("number").ForUpdate().Get(&last)
+	if err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	var active m.VotingSession
+	hasActive, err := session.Where("room_id = ? AND active = ?", roomId.Id, true).Get(&active)
+	if err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	next := &m.VotingSession{RoomID: roomId.Id, Number: 1, Active: true, Official: !hasActive || active.Official}
+	if hasLast {
+		next.Number = last.Number + 1
+	}
+
+	if hasActive {
+		now := time.Now()
+		_, err = session.ID(active.ID).Cols("active", "archived_at").Update(&m.VotingSession{Active: false, ArchivedAt: &now})
+		if err != nil {
+			session.Rollback()
+			return nil, err
+		}
+	}
+
+	if next.Official {
+		_, err = session.Where("room_id = ?", roomId.Id).Cols("official").Update(&m.VotingSession{Official: false})
+		if err != nil {
+			session.Rollback()
+			return nil, err
+		}
+	}
+
+	if _, err := session.Insert(next); err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	//el progreso guardado era de la sesion anterior
+	if _, err := session.Where("room_id = ?", roomId.Id).Delete(&m.LobbyState{}); err != nil {
+		session.Rollback()
+		return nil, err
+	}
+
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sessionToDomain(next), nil
+}
+
+func (s *RoomXormRepository) ActiveSession(roomId sv.ID) (*d.VotingSession, error) {
+	model := new(m.VotingSession)
+	has, err := s.db.GetDb().Where("room_id = ? AND active = ?", roomId.Id, true).Get(model)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		return sessionToDomain(model), nil
+	}
+
+	//primera sesion de la sala, el indice unico evita que dos instancias la creen a la vez
+	_, err = s.db.GetDb().Exec(`
+		INSERT INTO voting_session (room_id, number, active, official, started_at)
+		VALUES (?, 1, true, true, NOW())
+		ON CONFLICT DO NOTHING
+	`, roomId.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	has, err = s.db.GetDb().Where("room_id = ? AND active = ?", roomId.Id, true).Get(model)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, re.ErrSessionNotFound
+	}
+	return sessionToDomain(model), nil
+}
+
+func (s *RoomXormRepository) GetSessions(roomId sv.ID) ([]d.VotingSession, error) {
+	var models []m.VotingSession
+	if err := s.db.GetDb().Where("room_id = ?", roomId.Id).Asc("number").Find(&models); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]d.VotingSession, 0, len(models))
+	for i := range models {
+		sessions = append(sessions, *sessionToDomain(&models[i]))
+	}
+	return sessions, nil
+}
+
+func (s *RoomXormRepository) SetOfficialSession(roomId sv.ID, sessionId sv.ID) error {
+	exists, err := s.db.GetDb().Exist(&m.VotingSession{ID: sessionId.Id, RoomID: roomId.Id})
 	if err != nil {
 		return err
 	}
+	if !exists {
+		return re.ErrSessionNotFound
+	}
 
-	return nil
+	_, err = s.db.GetDb().Exec(`
+		UPDATE voting_session
+		SET official = (id = ?)
+		WHERE room_id = ?
+	`, sessionId.Id, roomId.Id)
+	return err
+}
+
+func sessionToDomain(model *m.VotingSession) *d.VotingSession {
+	return &d.VotingSession{
+		ID:         model.ID,
+		RoomID:     model.RoomID,
+		Number:     model.Number,
+		Active:     model.Active,
+		Official:   model.Official,
+		StartedAt:  model.StartedAt,
+		ArchivedAt: model.ArchivedAt,
+	}
+}
+
+func (s *RoomXormRepository) SaveLobbyState(roomId sv.ID, state d.LobbyState) error {
+	model := &m.LobbyState{
+		RoomID:         roomId.Id,
+		NextProposal:   state.NextProposal,
+		VotingOpen:     state.VotingOpen,
+		VotingProposal: state.VotingProposal,
+		ClosesAt:       state.ClosesAt,
+		Remaining:      state.Remaining,
+		Round:          state.Round,
+	}
+
+	exists, err := s.db.GetDb().Exist(&m.LobbyState{RoomID: roomId.Id})
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err = s.db.GetDb().Insert(model)
+		return err
+	}
+
+	_, err = s.db.GetDb().ID(roomId.Id).
+		Cols("next_proposal", "voting_open", "voting_proposal", "closes_at", "remaining", "round").
+		Update(model)
+	return err
+}
+
+func (s *RoomXormRepository) GetLobbyState(roomId sv.ID) (*d.LobbyState, error) {
+	model := new(m.LobbyState)
+	has, err := s.db.GetDb().ID(roomId.Id).Get(model)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+
+	return &d.LobbyState{
+		NextProposal:   model.NextProposal,
+		VotingOpen:     model.VotingOpen,
+		VotingProposal: model.VotingProposal,
+		ClosesAt:       model.ClosesAt,
+		Remaining:      model.Remaining,
+		Round:          model.Round,
+	}, nil
+}
+
+func (s *RoomXormRepository) GetRoles(roomId sv.ID) (map[uint]string, error) {
+	var roles []m.RoomRole
+	err := s.db.GetDb().Where("room_id = ?", roomId.Id).Find(&roles)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]string)
+	for _, role := range roles {
+		result[role.UserID] = role.Role
+	}
+	return result, nil
+}
+
+// voter es el rol por defecto, asignarlo borra el registro
+func (s *RoomXormRepository) SetRole(roomId sv.ID, userId sv.ID, role string) error {
+	_, err := s.db.GetDb().Where("room_id = ? AND user_id = ?", roomId.Id, userId.Id).Delete(&m.RoomRole{})
+	if err != nil {
+		return err
+	}
+
+	if role == v.RoleVoter {
+		return nil
+	}
+
+	_, err = s.db.GetDb().Insert(&m.RoomRole{RoomID: roomId.Id, UserID: userId.Id, Role: role})
+	return err
+}
+
+// el nuevo dueño pierde su rol anterior y el dueño anterior queda como co_admin
+func (s *RoomXormRepository) TransferOwnership(roomId sv.ID, newOwnerId sv.ID) error {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	var room m.Room
+	has, err := session.ID(roomId.Id).Get(&room)
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+	if !has {
+		session.Rollback()
+		return re.ErrRoomNotFound
+	}
+
+	previousOwner := room.AdminID
+	room.AdminID = newOwnerId.Id
+	if _, err := session.ID(roomId.Id).Cols("admin_id").Update(&room); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	_, err = session.Where("room_id = ? AND user_id IN (?, ?)", roomId.Id, newOwnerId.Id, previousOwner).Delete(&m.RoomRole{})
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	if _, err := session.Insert(&m.RoomRole{RoomID: roomId.Id, UserID: previousOwner, Role: v.RoleCoAdmin}); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
 }
 
 func (s *RoomXormRepository) HistoryRooms(userId sv.ID) ([]d.Room, error) {
@@ -298,3 +618,128 @@ func (s *RoomXormRepository) HistoryRooms(userId sv.ID) ([]d.Room, error) {
 
 	return rooms, nil
 }
+
+func (s *RoomXormRepository) SaveChatMessage(message d.ChatMessage) (*d.ChatMessage, error) {
+	model := &m.ChatMessage{
+		RoomID:   message.RoomID,
+		UserID:   message.UserID,
+		Username: message.Username,
+		Message:  message.Message,
+	}
+
+	if _, err := s.db.GetDb().Insert(model); err != nil {
+		return nil, err
+	}
+
+	message.ID = model.ID
+	message.SentAt = model.SentAt
+	return &message, nil
+}
+
+func (s *RoomXormRepository) GetChatMessages(roomId sv.ID, limit int) ([]d.ChatMessage, error) {
+	var models []m.ChatMessage
+	err := s.db.GetDb().Where("room_id = ?", roomId.Id).Desc("id").Limit(limit).Find(&models)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]d.ChatMessage, 0, len(models))
+	for i := len(models) - 1; i >= 0; i-- {
+		messages = append(messages, d.ChatMessage{
+			ID:       models[i].ID,
+			RoomID:   models[i].RoomID,
+			UserID:   models[i].UserID,
+			Username: models[i].Username,
+			Message:  models[i].Message,
+			SentAt:   models[i].SentAt,
+		})
+	}
+	return messages, nil
+}
+
+// borrado logico, se guarda el moderador que lo borro
+func (s *RoomXormRepository) DeleteChatMessage(roomId sv.ID, messageId sv.ID, deletedBy sv.ID) error {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	affected, err := session.Where("id = ? AND room_id = ?", messageId.Id, roomId.Id).
+		Cols("deleted_by").
+		Update(&m.ChatMessage{DeletedBy: &deletedBy.Id})
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+	if affected == 0 {
+		session.Rollback()
+		return re.ErrMessageNotFound
+	}
+
+	if _, err := session.Where("id = ?", messageId.Id).Delete(&m.ChatMessage{}); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+// el update condicional evita pisar un cambio de estado hecho por otra instancia
+func (s *RoomXormRepository) ChangeState(transition d.StateTransition) error {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	affected, err := session.Where("id = ? AND state = ?", transition.RoomID, transition.From).
+		Cols("state").
+		Update(&m.Room{State: transition.To})
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+	if affected == 0 {
+		session.Rollback()
+		return fmt.Errorf("%w: room id = %d is no longer %q", re.ErrInvalidTransition, transition.RoomID, transition.From)
+	}
+
+	_, err = session.Insert(&m.RoomStateTransition{
+		RoomID:    transition.RoomID,
+		FromState: transition.From,
+		ToState:   transition.To,
+		ActorID:   transition.ActorID,
+		Reason:    transition.Reason,
+	})
+	if err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+func (s *RoomXormRepository) GetTimeline(roomId sv.ID) ([]d.StateTransition, error) {
+	var models []m.RoomStateTransition
+	err := s.db.GetDb().Where("room_id = ?", roomId.Id).Asc("created_at", "id").Find(&models)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]d.StateTransition, 0, len(models))
+	for _, model := range models {
+		timeline = append(timeline, d.StateTransition{
+			ID:        model.ID,
+			RoomID:    model.RoomID,
+			From:      model.FromState,
+			To:        model.ToState,
+			ActorID:   model.ActorID,
+			Reason:    model.Reason,
+			CreatedAt: model.CreatedAt,
+		})
+	}
+	return timeline, nil
+}
@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	roomdom "suffgo/internal/rooms/domain"
+	roomerr "suffgo/internal/rooms/domain/errors"
+	roomvo "suffgo/internal/rooms/domain/valueObjects"
+	d "suffgo/internal/scheduler/domain"
+	sv "suffgo/internal/shared/domain/valueObjects"
+)
+
+// una pasada del scheduler: avisa antes del inicio, abre las salas a su horario
+// y cierra las que no empezaron dentro del plazo
+type RunUsecase struct {
+	scheduleRepo d.ScheduleRepository
+	roomRepo     roomdom.RoomRepository
+	lobbies      roomdom.LobbyCloser
+	notifier     d.Notifier
+	reminderLead time.Duration //anticipacion del recordatorio
+	closeWindow  time.Duration //plazo desde el inicio para que la votacion empiece
+}
+
+func NewRunUsecase(
+	scheduleRepo d.ScheduleRepository,
+	roomRepo roomdom.RoomRepository,
+	lobbies roomdom.LobbyCloser,
+	notifier d.Notifier,
+	reminderLead time.Duration,
+	closeWindow time.Duration,
+) *RunUsecase {
+	return &RunUsecase{
+		scheduleRepo: scheduleRepo,
+		roomRepo:     roomRepo,
+		lobbies:      lobbies,
+		notifier:     notifier,
+		reminderLead: reminderLead,
+		closeWindow:  closeWindow,
+	}
+}
+
+func (s *RunUsecase) Execute(now time.Time) error {
+	rooms, err := s.scheduleRepo.Pending()
+	if err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		//las salas con horario futuro pasan a scheduled hasta que se abren
+		if room.State == roomvo.StateCreated && now.Before(room.StartTime) {
+			err := s.changeState(room.RoomID, roomvo.StateScheduled, "start time set")
+			if err == nil {
+				room.State = roomvo.StateScheduled
+			} else if !errors.Is(err, roomerr.ErrInvalidTransition) {
+				log.Printf("error scheduling room id = %d: %v \n", room.RoomID, err)
+			}
+		}
+
+		//queda registrado que el scheduler programo la sala para este horario
+		if room.State == roomvo.StateScheduled && !room.Scheduled {
+			if _, err := s.scheduleRepo.Claim(room.RoomID, d.StepScheduled); err != nil {
+				log.Printf("error scheduling room id = %d: %v \n", room.RoomID, err)
+			} else {
+				room.Scheduled = true
+			}
+		}
+
+		//solo se abren y cierran solas las salas que programo el scheduler: las que quedaron created
+		//(por ejemplo porque se fueron todos) o tenian horario antes de existir el scheduler no se tocan
+		scheduled := room.State == roomvo.StateScheduled
+		var step string
+		switch {
+		case !room.Closed && (scheduled || (room.Scheduled && room.State == roomvo.StateOnline)) && !now.Before(room.StartTime.Add(s.closeWindow)):
+			step = d.StepClosed
+		case !room.Opened && scheduled && !now.Before(room.StartTime):
+			step = d.StepOpened
+		case !room.Reminded && scheduled && now.Before(room.StartTime) && !now.Before(room.StartTime.Add(-s.reminderLead)):
+			step = d.StepReminded
+		default:
+			continue
+		}
+
+		//otra instancia pudo haber ejecutado el paso
+		claimed, err := s.scheduleRepo.Claim(room.RoomID, step)
+		if err != nil {
+			log.Printf("error claiming %s of room id = %d: %v \n", step, room.RoomID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		//si fallo se libera el paso para reintentarlo en la proxima pasada
+		if err := s.run(room, step); err != nil {
+			log.Printf("error running %s of room id = %d: %v \n", step, room.RoomID, err)
+			if err := s.scheduleRepo.Release(room.RoomID, step); err != nil {
+				log.Printf("error releasing %s of room id = %d: %v \n", step, room.RoomID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *RunUsecase) run(room d.ScheduledRoom, step string) error {
+	roomId, err := sv.NewID(room.RoomID)
+	if err != nil {
+		return err
+	}
+
+	kind := d.NotificationReminder
+	switch step {
+	case d.StepOpened:
+		kind = d.NotificationOpened
+		//el administrador pudo haberla abierto mientras tanto
+		if err := s.changeState(room.RoomID, roomvo.StateOnline, "start time reached"); err != nil && !errors.Is(err, roomerr.ErrInvalidTransition) {
+			return err
+		}
+	case d.StepClosed:
+		kind = d.NotificationClosed
+		current, err := s.roomRepo.GetByID(*roomId)
+		if err != nil {
+			return err
+		}
+		//la votacion empezo o la sala volvio a created despues de leer las salas pendientes
+		if state := current.State().CurrentState; state != roomvo.StateScheduled && state != roomvo.StateOnline {
+			return nil
+		}
+		s.lobbies.CloseLobby(*roomId, d.CloseReasonExpired)
+	}
+
+	users, err := s.scheduleRepo.Recipients(room.RoomID)
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(d.Notification{
+		Kind:      kind,
+		RoomID:    room.RoomID,
+		RoomName:  room.Name,
+		StartTime: room.StartTime,
+		UserIDs:   users,
+	})
+}
+
+// el cambio lo hace el sistema, falla si otra instancia ya cambio el estado
+func (s *RunUsecase) changeState(roomID uint, state string, reason string) error {
+	roomId, err := sv.NewID(roomID)
+	if err != nil {
+		return err
+	}
+
+	room, err := s.roomRepo.GetByID(*roomId)
+	if err != nil {
+		return err
+	}
+
+	transition, err := room.ChangeState(state, nil, reason)
+	if err != nil {
+		return err
+	}
+	return s.roomRepo.ChangeState(*transition)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const (
+	NotificationReminder = "reminder" //la sala empieza pronto
+	NotificationOpened   = "opened"   //la sala se abrio a su horario
+	NotificationClosed   = "closed"   //la sala no empezo dentro del plazo y se cerro
+)
+
+type Notification struct {
+	Kind      string    `json:"kind"`
+	RoomID    uint      `json:"room_id"`
+	RoomName  string    `json:"room_name"`
+	StartTime time.Time `json:"start_time"`
+	UserIDs   []uint    `json:"user_ids"`
+}
+
+type Notifier interface {
+	Notify(notification Notification) error
+}
@@ -0,0 +1,10 @@
+package domain
+
+type ScheduleRepository interface {
+	Pending() ([]ScheduledRoom, error) //salas created, scheduled u online con horario de inicio
+	// reserva el paso para el horario de inicio actual, false si otra instancia ya lo ejecuto
+	Claim(roomID uint, step string) (bool, error)
+	// libera el paso reservado para que se vuelva a intentar, se usa si fallo
+	Release(roomID uint, step string) error
+	Recipients(roomID uint) ([]uint, error) //dueño y usuarios de la whitelist
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// pasos que el scheduler ejecuta una sola vez por horario de inicio
+const (
+	StepScheduled = "scheduled"
+	StepReminded  = "reminded"
+	StepOpened    = "opened"
+	StepClosed    = "closed"
+)
+
+// motivo que reciben los clientes de la sala al cerrarse
+const CloseReasonExpired = "expired"
+
+// sala con horario de inicio que todavia no empezo
+type ScheduledRoom struct {
+	RoomID    uint
+	Name      string
+	State     string
+	StartTime time.Time
+	Scheduled bool //el scheduler la paso a scheduled para este horario
+	Reminded  bool //los pasos se reinician si cambia el horario de inicio
+	Opened    bool
+	Closed    bool
+}
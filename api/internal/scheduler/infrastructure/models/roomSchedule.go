@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// pasos del scheduler ya ejecutados para el horario de inicio de la sala
+type RoomSchedule struct {
+	RoomID      uint       `xorm:"'room_id' pk"`
+	StartTime   time.Time  `xorm:"'start_time' not null"`
+	ScheduledAt *time.Time `xorm:"'scheduled_at' null"`
+	RemindedAt  *time.Time `xorm:"'reminded_at' null"`
+	OpenedAt    *time.Time `xorm:"'opened_at' null"`
+	ClosedAt    *time.Time `xorm:"'closed_at' null"`
+}
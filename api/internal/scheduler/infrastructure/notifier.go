@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	d "suffgo/internal/scheduler/domain"
+)
+
+// deja registro de los avisos, se usa si no hay webhook configurado
+type LogNotifier struct{}
+
+func (n LogNotifier) Notify(notification d.Notification) error {
+	log.Printf("scheduler: %s room id = %d (%s) starting at %s, %d users \n",
+		notification.Kind, notification.RoomID, notification.RoomName,
+		notification.StartTime.Format(time.RFC3339), len(notification.UserIDs))
+	return nil
+}
+
+// envia los avisos por POST a un servicio externo (mails, push, etc)
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Notify(notification d.Notification) error {
+	LogNotifier{}.Notify(notification)
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	res, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"fmt"
+	"time"
+
+	"suffgo/cmd/database"
+	d "suffgo/internal/scheduler/domain"
+)
+
+type ScheduleXormRepository struct {
+	db database.Database
+}
+
+func NewScheduleXormRepository(db database.Database) *ScheduleXormRepository {
+	return &ScheduleXormRepository{
+		db: db,
+	}
+}
+
+type pendingRow struct {
+	RoomID         uint       `xorm:"'room_id'"`
+	Name           string     `xorm:"'name'"`
+	State          string     `xorm:"'state'"`
+	StartTime      time.Time  `xorm:"'start_time'"`
+	ScheduledStart *time.Time `xorm:"'scheduled_start'"`
+	ScheduledAt    *time.Time `xorm:"'scheduled_at'"`
+	RemindedAt     *time.Time `xorm:"'reminded_at'"`
+	OpenedAt       *time.Time `xorm:"'opened_at'"`
+	ClosedAt       *time.Time `xorm:"'closed_at'"`
+}
+
+func (s *ScheduleXormRepository) Pending() ([]d.ScheduledRoom, error) {
+	var rows []pendingRow
+	err := s.db.GetDb().SQL(`
+		SELECT r.id AS room_id, r.name, r.state, sr.start_time,
+			rs.start_time AS scheduled_start, rs.scheduled_at, rs.reminded_at, rs.opened_at, rs.closed_at
+		FROM settings_room sr
+		JOIN room r ON r.id = sr.room_id
+		LEFT JOIN room_schedule rs ON rs.room_id = r.id
+		WHERE sr.start_time IS NOT NULL
+			AND r.deleted_at IS NULL
+			AND r.state IN ('created', 'scheduled', 'online')
+	`).Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]d.ScheduledRoom, 0, len(rows))
+	for _, row := range rows {
+		room := d.ScheduledRoom{
+			RoomID:    row.RoomID,
+			Name:      row.Name,
+			State:     row.State,
+			StartTime: row.StartTime,
+		}
+
+		//si cambio el horario los pasos anteriores no cuentan
+		if row.ScheduledStart != nil && row.ScheduledStart.Equal(row.StartTime) {
+			room.Scheduled = row.ScheduledAt != nil
+			room.Reminded = row.RemindedAt != nil
+			room.Opened = row.OpenedAt != nil
+			room.Closed = row.ClosedAt != nil
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// el upsert reinicia los pasos cuando cambia el horario de inicio. El update condicional
+// garantiza que una sola instancia ejecute cada paso
+func (s *ScheduleXormRepository) Claim(roomID uint, step string) (bool, error) {
+	column, err := stepColumn(step)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.db.GetDb().Exec(`
+		INSERT INTO room_schedule (room_id, start_time)
+		SELECT room_id, start_time FROM settings_room
+		WHERE room_id = ? AND start_time IS NOT NULL
+		LIMIT 1
+		ON CONFLICT (room_id) DO UPDATE
+		SET start_time = EXCLUDED.start_time, scheduled_at = NULL, reminded_at = NULL, opened_at = NULL, closed_at = NULL
+		WHERE room_schedule.start_time <> EXCLUDED.start_time
+	`, roomID)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.db.GetDb().Exec(fmt.Sprintf(`
+		UPDATE room_schedule SET %[1]s = NOW()
+		WHERE room_id = ? AND %[1]s IS NULL
+	`, column), roomID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (s *ScheduleXormRepository) Release(roomID uint, step string) error {
+	column, err := stepColumn(step)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.GetDb().Exec(fmt.Sprintf(`UPDATE room_schedule SET %s = NULL WHERE room_id = ?`, column), roomID)
+	return err
+}
+
+func stepColumn(step string) (string, error) {
+	switch step {
+	case d.StepScheduled:
+		return "scheduled_at", nil
+	case d.StepReminded:
+		return "reminded_at", nil
+	case d.StepOpened:
+		return "opened_at", nil
+	case d.StepClosed:
+		return "closed_at", nil
+	}
+	return "", fmt.Errorf("unknown schedule step %q", step)
+}
+
+func (s *ScheduleXormRepository) Recipients(roomID uint) ([]uint, error) {
+	var rows []struct {
+		UserID uint `xorm:"'user_id'"`
+	}
+	err := s.db.GetDb().SQL(`
+		SELECT user_id FROM user_room WHERE room_id = ?
+		UNION
+		SELECT admin_id FROM room WHERE id = ?
+	`, roomID, roomID).Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, row.UserID)
+	}
+	return users, nil
+}
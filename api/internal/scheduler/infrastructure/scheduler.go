@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"log"
+	"time"
+
+	usecases "suffgo/internal/scheduler/application/useCases"
+)
+
+// ejecuta el scheduler periodicamente. Todo el estado esta en la base,
+// por lo que sobrevive a reinicios y puede correr en varias instancias
+type Scheduler struct {
+	run      *usecases.RunUsecase
+	interval time.Duration
+	stop     chan struct{}
+}
+
+func NewScheduler(run *usecases.RunUsecase, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		run:      run,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+func (s *Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.run.Execute(time.Now()); err != nil {
+				log.Printf("scheduler error: %v \n", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) Stop() {
+	close(s.stop)
+}
@@ -14,6 +14,10 @@ type (
 		quorum        *v.Quorum
 		startTime     *v.DateTime
 		voterLimit    v.VoterLimit //capacidad de la sala
+		waitingList   *v.WaitingList
+		secretBallot  *v.SecretBallot
+		abstainQuorum *v.AbstainQuorum
+		voteChange    *v.VoteChange
 		roomID        *sv.ID
 	}
 
@@ -22,8 +26,13 @@ type (
 		Privacy       *bool      `json:"privacy"`
 		ProposalTimer int        `json:"proposal_timer"`
 		Quorum        *int       `json:"quorum"`
+		QuorumType    string     `json:"quorum_type"`
 		DateTime      *time.Time `json:"start_time"`
 		VoterLimit    int        `json:"voter_limit"`
+		WaitingList   *bool      `json:"waiting_list"`
+		SecretBallot  *bool      `json:"secret_ballot"`
+		AbstainQuorum *bool      `json:"abstain_quorum"`
+		VoteChange    *bool      `json:"vote_change"`
 		RoomID        uint       `json:"room_id"`
 	}
 
@@ -31,8 +40,13 @@ type (
 		Privacy       *bool      `json:"privacy"`
 		ProposalTimer int        `json:"proposal_timer"`
 		Quorum        *int       `json:"quorum"`
+		QuorumType    string     `json:"quorum_type"`
 		DateTime      *time.Time `json:"start_time"`
 		VoterLimit    int        `json:"voter_limit"`
+		WaitingList   *bool      `json:"waiting_list"`
+		SecretBallot  *bool      `json:"secret_ballot"`
+		AbstainQuorum *bool      `json:"abstain_quorum"`
+		VoteChange    *bool      `json:"vote_change"`
 		RoomID        uint       `json:"room_id"`
 	}
 )
@@ -44,6 +58,10 @@ func NewSettingRoom(
 	quorum v.Quorum,
 	startTime v.DateTime,
 	voterLimit v.VoterLimit,
+	waitingList v.WaitingList,
+	secretBallot v.SecretBallot,
+	abstainQuorum v.AbstainQuorum,
+	voteChange v.VoteChange,
 	roomID *sv.ID,
 ) *SettingRoom {
 	return &SettingRoom{
@@ -53,6 +71,10 @@ func NewSettingRoom(
 		quorum:        &quorum,
 		startTime:     &startTime,
 		voterLimit:    voterLimit,
+		waitingList:   &waitingList,
+		secretBallot:  &secretBallot,
+		abstainQuorum: &abstainQuorum,
+		voteChange:    &voteChange,
 		roomID:        roomID,
 	}
 }
@@ -81,6 +103,22 @@ func (s *SettingRoom) VoterLimit() v.VoterLimit {
 	return s.voterLimit
 }
 
+func (s *SettingRoom) WaitingList() v.WaitingList {
+	return *s.waitingList
+}
+
+func (s *SettingRoom) SecretBallot() v.SecretBallot {
+	return *s.secretBallot
+}
+
+func (s *SettingRoom) AbstainQuorum() v.AbstainQuorum {
+	return *s.abstainQuorum
+}
+
+func (s *SettingRoom) VoteChange() v.VoteChange {
+	return *s.voteChange
+}
+
 func (s *SettingRoom) RoomID() sv.ID {
 	return *s.roomID
 }
@@ -0,0 +1,22 @@
+package valueobjects
+
+type (
+	AbstainQuorum struct {
+		AbstainQuorum *bool //si esta activo las abstenciones y los votos en blanco cuentan para el quorum
+	}
+)
+
+func NewAbstainQuorum(abstainQuorum *bool) (*AbstainQuorum, error) {
+	if abstainQuorum == nil {
+		t := true
+		abstainQuorum = &t
+	}
+
+	return &AbstainQuorum{
+		AbstainQuorum: abstainQuorum,
+	}, nil
+}
+
+func (a AbstainQuorum) Enabled() bool {
+	return a.AbstainQuorum == nil || *a.AbstainQuorum
+}
@@ -1,14 +1,54 @@
 package valueobjects
 
+import "errors"
+
+const (
+	QuorumAbsolute   = "absolute"
+	QuorumPercentage = "percentage"
+)
+
 type (
 	Quorum struct {
-		Quorum *int //esta en
+		Quorum *int   //cantidad de usuarios o porcentaje de la whitelist segun Type
+		Type   string //absolute | percentage
 	}
 )
 
-func NewQuorum(quorum *int) (*Quorum, error) {
+func NewQuorum(quorum *int, quorumType string) (*Quorum, error) {
+
+	if quorumType == "" {
+		quorumType = QuorumAbsolute
+	}
+
+	if quorumType != QuorumAbsolute && quorumType != QuorumPercentage {
+		return nil, errors.New("invalid quorum type")
+	}
+
+	if quorum != nil {
+		if *quorum < 0 {
+			return nil, errors.New("invalid quorum")
+		}
+		if quorumType == QuorumPercentage && *quorum > 100 {
+			return nil, errors.New("quorum percentage must be between 0 and 100")
+		}
+	}
 
 	return &Quorum{
 		Quorum: quorum,
+		Type:   quorumType,
 	}, nil
 }
+
+// cantidad minima de usuarios necesaria en base al tamaño de la whitelist
+func (q Quorum) Required(whitelistSize int) int {
+	if q.Quorum == nil || *q.Quorum == 0 {
+		return 0
+	}
+
+	if q.Type == QuorumPercentage {
+		//redondeo hacia arriba
+		return (*q.Quorum*whitelistSize + 99) / 100
+	}
+
+	return *q.Quorum
+}
@@ -0,0 +1,22 @@
+package valueobjects
+
+type (
+	SecretBallot struct {
+		SecretBallot *bool //si esta activo los resultados no exponen quien voto que
+	}
+)
+
+func NewSecretBallot(secretBallot *bool) (*SecretBallot, error) {
+	if secretBallot == nil {
+		f := false
+		secretBallot = &f
+	}
+
+	return &SecretBallot{
+		SecretBallot: secretBallot,
+	}, nil
+}
+
+func (s SecretBallot) Enabled() bool {
+	return s.SecretBallot != nil && *s.SecretBallot
+}
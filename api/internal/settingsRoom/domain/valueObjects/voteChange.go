@@ -0,0 +1,22 @@
+package valueobjects
+
+type (
+	VoteChange struct {
+		VoteChange *bool //si esta activo el votante puede cambiar su boleta hasta que se cierre la propuesta
+	}
+)
+
+func NewVoteChange(voteChange *bool) (*VoteChange, error) {
+	if voteChange == nil {
+		f := false
+		voteChange = &f
+	}
+
+	return &VoteChange{
+		VoteChange: voteChange,
+	}, nil
+}
+
+func (v VoteChange) Enabled() bool {
+	return v.VoteChange != nil && *v.VoteChange
+}
@@ -0,0 +1,22 @@
+package valueobjects
+
+type (
+	WaitingList struct {
+		WaitingList *bool //si la sala esta llena los usuarios esperan su turno en vez de ser rechazados
+	}
+)
+
+func NewWaitingList(waitingList *bool) (*WaitingList, error) {
+	if waitingList == nil {
+		f := false
+		waitingList = &f
+	}
+
+	return &WaitingList{
+		WaitingList: waitingList,
+	}, nil
+}
+
+func (w WaitingList) Enabled() bool {
+	return w.WaitingList != nil && *w.WaitingList
+}
@@ -13,8 +13,13 @@ func DomainToModel(settingRoom *domain.SettingRoom) *m.SettingsRoom {
 		Privacy:       settingRoom.Privacy().Privacy,
 		ProposalTimer: settingRoom.ProposalTimer().ProposalTimer,
 		Quorum:        settingRoom.Quorum().Quorum,
+		QuorumType:    settingRoom.Quorum().Type,
 		DateTime:      settingRoom.DateTime().DateTime,
 		VoterLimit:    settingRoom.VoterLimit().VoterLimit,
+		WaitingList:   settingRoom.WaitingList().WaitingList,
+		SecretBallot:  settingRoom.SecretBallot().SecretBallot,
+		AbstainQuorum: settingRoom.AbstainQuorum().AbstainQuorum,
+		VoteChange:    settingRoom.VoteChange().VoteChange,
 		RoomID:        settingRoom.RoomID().Id,
 	}
 }
@@ -33,7 +38,7 @@ func ModelToDomain(settingRoomModel *m.SettingsRoom) (*domain.SettingRoom, error
 	if err != nil {
 		return nil, err
 	}
-	quorum, err := v.NewQuorum(settingRoomModel.Quorum)
+	quorum, err := v.NewQuorum(settingRoomModel.Quorum, settingRoomModel.QuorumType)
 	if err != nil {
 		return nil, err
 	}
@@ -46,9 +51,29 @@ func ModelToDomain(settingRoomModel *m.SettingsRoom) (*domain.SettingRoom, error
 		return nil, err
 	}
 
+	waitingList, err := v.NewWaitingList(settingRoomModel.WaitingList)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBallot, err := v.NewSecretBallot(settingRoomModel.SecretBallot)
+	if err != nil {
+		return nil, err
+	}
+
+	abstainQuorum, err := v.NewAbstainQuorum(settingRoomModel.AbstainQuorum)
+	if err != nil {
+		return nil, err
+	}
+
+	voteChange, err := v.NewVoteChange(settingRoomModel.VoteChange)
+	if err != nil {
+		return nil, err
+	}
+
 	room, err := sv.NewID(settingRoomModel.RoomID)
 	if err != nil {
 		return nil, err
 	}
-	return domain.NewSettingRoom(id, *privacy, proposalTimer, *quorum, *startTime, voterLimit, room), nil
+	return domain.NewSettingRoom(id, *privacy, proposalTimer, *quorum, *startTime, voterLimit, *waitingList, *secretBallot, *abstainQuorum, *voteChange, room), nil
 }
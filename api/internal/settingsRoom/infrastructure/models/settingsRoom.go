@@ -5,9 +5,14 @@ import "time"
 type SettingsRoom struct {
 	ID            uint       `xorm:"'id' pk autoincr"`
 	Quorum        *int       `xorm:"'quorum' null"`
+	QuorumType    string     `xorm:"'quorum_type' varchar(16) not null default 'absolute'"`
 	Privacy       *bool      `xorm:"'privacy' not null default false"`
 	VoterLimit    int        `xorm:"'voter_limit' not null default 0"`
 	DateTime      *time.Time `xorm:"'start_time' null"`
 	ProposalTimer int        `xorm:"'proposal_timer' not null default 60"` //despues vemos que onda si es minutos o segundos
+	WaitingList   *bool      `xorm:"'waiting_list' not null default false"`
+	SecretBallot  *bool      `xorm:"'secret_ballot' not null default false"`
+	AbstainQuorum *bool      `xorm:"'abstain_quorum' not null default true"`
+	VoteChange    *bool      `xorm:"'vote_change' not null default false"`
 	RoomID        uint       `xorm:"'room_id' index not null"`
 }
@@ -58,7 +58,7 @@ func (h *SettingRoomEchoHandler) CreateSettingRoom(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	quorum, err := v.NewQuorum(req.Quorum)
+	quorum, err := v.NewQuorum(req.Quorum, req.QuorumType)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -73,6 +73,26 @@ func (h *SettingRoomEchoHandler) CreateSettingRoom(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	waitingList, err := v.NewWaitingList(req.WaitingList)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	secretBallot, err := v.NewSecretBallot(req.SecretBallot)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	abstainQuorum, err := v.NewAbstainQuorum(req.AbstainQuorum)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	voteChange, err := v.NewVoteChange(req.VoteChange)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	roomID, err := sv.NewID(req.RoomID)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -95,6 +115,10 @@ func (h *SettingRoomEchoHandler) CreateSettingRoom(c echo.Context) error {
 		*quorum,
 		*timeAndDate,
 		voterLimit,
+		*waitingList,
+		*secretBallot,
+		*abstainQuorum,
+		*voteChange,
 		roomID,
 	)
 
@@ -153,8 +177,13 @@ func (h *SettingRoomEchoHandler) GetAllSettingRoom(c echo.Context) error {
 			Privacy:       settingRoom.Privacy().Privacy,
 			ProposalTimer: settingRoom.ProposalTimer().ProposalTimer,
 			Quorum:        settingRoom.Quorum().Quorum,
+			QuorumType:    settingRoom.Quorum().Type,
 			DateTime:      settingRoom.DateTime().DateTime,
 			VoterLimit:    settingRoom.VoterLimit().VoterLimit,
+			WaitingList:   settingRoom.WaitingList().WaitingList,
+			SecretBallot:  settingRoom.SecretBallot().SecretBallot,
+			AbstainQuorum: settingRoom.AbstainQuorum().AbstainQuorum,
+			VoteChange:    settingRoom.VoteChange().VoteChange,
 			RoomID:        settingRoom.RoomID().Id,
 		}
 		settingsRoomDTO = append(settingsRoomDTO, *SettingRoomDTO)
@@ -185,8 +214,13 @@ func (h *SettingRoomEchoHandler) GetSettingRoomByID(c echo.Context) error {
 		Privacy:       settingRoom.Privacy().Privacy,
 		ProposalTimer: settingRoom.ProposalTimer().ProposalTimer,
 		Quorum:        settingRoom.Quorum().Quorum,
+		QuorumType:    settingRoom.Quorum().Type,
 		DateTime:      settingRoom.DateTime().DateTime,
 		VoterLimit:    settingRoom.VoterLimit().VoterLimit,
+		WaitingList:   settingRoom.WaitingList().WaitingList,
+		SecretBallot:  settingRoom.SecretBallot().SecretBallot,
+		AbstainQuorum: settingRoom.AbstainQuorum().AbstainQuorum,
+		VoteChange:    settingRoom.VoteChange().VoteChange,
 		RoomID:        settingRoom.RoomID().Id,
 	}
 	return c.JSON(http.StatusOK, settingRoomDTO)
@@ -214,8 +248,13 @@ func (h *SettingRoomEchoHandler) GetSrByRoomID(c echo.Context) error {
 		Privacy:       settingRoom.Privacy().Privacy,
 		ProposalTimer: settingRoom.ProposalTimer().ProposalTimer,
 		Quorum:        settingRoom.Quorum().Quorum,
+		QuorumType:    settingRoom.Quorum().Type,
 		DateTime:      settingRoom.DateTime().DateTime,
 		VoterLimit:    settingRoom.VoterLimit().VoterLimit,
+		WaitingList:   settingRoom.WaitingList().WaitingList,
+		SecretBallot:  settingRoom.SecretBallot().SecretBallot,
+		AbstainQuorum: settingRoom.AbstainQuorum().AbstainQuorum,
+		VoteChange:    settingRoom.VoteChange().VoteChange,
 		RoomID:        settingRoom.RoomID().Id,
 	}
 	return c.JSON(http.StatusOK, settingRoomDTO)
@@ -254,7 +293,7 @@ func (h *SettingRoomEchoHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
-	Quorum, err := v.NewQuorum(req.Quorum)
+	Quorum, err := v.NewQuorum(req.Quorum, req.QuorumType)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
@@ -269,6 +308,26 @@ func (h *SettingRoomEchoHandler) Update(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	WaitingList, err := v.NewWaitingList(req.WaitingList)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	SecretBallot, err := v.NewSecretBallot(req.SecretBallot)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	AbstainQuorum, err := v.NewAbstainQuorum(req.AbstainQuorum)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	VoteChange, err := v.NewVoteChange(req.VoteChange)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	RoomID := curretSettings.RoomID()
 
 	settingRoom := d.NewSettingRoom(
@@ -278,6 +337,10 @@ func (h *SettingRoomEchoHandler) Update(c echo.Context) error {
 		*Quorum,
 		*DateTime,
 		VoterLimit,
+		*WaitingList,
+		*SecretBallot,
+		*AbstainQuorum,
+		*VoteChange,
 		&RoomID,
 	)
 
@@ -295,8 +358,13 @@ func (h *SettingRoomEchoHandler) Update(c echo.Context) error {
 		Privacy:       updatedSettingRoom.Privacy().Privacy,
 		ProposalTimer: updatedSettingRoom.ProposalTimer().ProposalTimer,
 		Quorum:        updatedSettingRoom.Quorum().Quorum,
+		QuorumType:    updatedSettingRoom.Quorum().Type,
 		DateTime:      updatedSettingRoom.DateTime().DateTime,
 		VoterLimit:    updatedSettingRoom.VoterLimit().VoterLimit,
+		WaitingList:   updatedSettingRoom.WaitingList().WaitingList,
+		SecretBallot:  updatedSettingRoom.SecretBallot().SecretBallot,
+		AbstainQuorum: updatedSettingRoom.AbstainQuorum().AbstainQuorum,
+		VoteChange:    updatedSettingRoom.VoteChange().VoteChange,
 		RoomID:        updatedSettingRoom.ID().Id,
 	}
 
@@ -77,8 +77,13 @@ func (s *SettingRoomXormRepository) Save(settingRoom d.SettingRoom) error {
 		Privacy:       settingRoom.Privacy().Privacy,
 		ProposalTimer: settingRoom.ProposalTimer().ProposalTimer,
 		Quorum:        settingRoom.Quorum().Quorum,
+		QuorumType:    settingRoom.Quorum().Type,
 		DateTime:      settingRoom.DateTime().DateTime,
 		VoterLimit:    settingRoom.VoterLimit().VoterLimit,
+		WaitingList:   settingRoom.WaitingList().WaitingList,
+		SecretBallot:  settingRoom.SecretBallot().SecretBallot,
+		AbstainQuorum: settingRoom.AbstainQuorum().AbstainQuorum,
+		VoteChange:    settingRoom.VoteChange().VoteChange,
 		RoomID:        settingRoom.RoomID().Id,
 	}
 	_, err := s.db.GetDb().Insert(settingRoomModel)
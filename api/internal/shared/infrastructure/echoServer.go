@@ -2,11 +2,14 @@ package infrastructure
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 	"suffgo/cmd/config"
 	"suffgo/cmd/database"
 
+	dlDom "suffgo/internal/delegations/domain"
 	optDom "suffgo/internal/options/domain"
 	propDom "suffgo/internal/proposals/domain"
 	roomDom "suffgo/internal/rooms/domain"
@@ -32,8 +35,17 @@ import (
 	roomUsecase "suffgo/internal/rooms/application/useCases"
 	roomUsecaseAddUsers "suffgo/internal/rooms/application/useCases/addUsers"
 	roomWsUsecase "suffgo/internal/rooms/application/useCases/websocket"
+	"suffgo/internal/rooms/application/useCases/websocket/socketStructs"
 
 	r "suffgo/internal/rooms/infrastructure"
+	lobbybus "suffgo/internal/rooms/infrastructure/lobbyBus"
+
+	schedulerUsecase "suffgo/internal/scheduler/application/useCases"
+	schedulerDom "suffgo/internal/scheduler/domain"
+	sc "suffgo/internal/scheduler/infrastructure"
+
+	delegationUsecase "suffgo/internal/delegations/application/useCases"
+	dl "suffgo/internal/delegations/infrastructure"
 
 	"github.com/gorilla/sessions"
 	"github.com/labstack/echo-contrib/session"
@@ -68,15 +80,19 @@ type Dependencies struct {
 	ProposalRepo    propDom.ProposalRepository
 	VotesRepo       voteDom.VoteRepository
 	OptionsRepo     optDom.OptionRepository
+	DelegationRepo  dlDom.DelegationRepository
+	LobbyBus        roomDom.LobbyBus
 }
 
-func NewDependencies(db database.Database) *Dependencies {
+func NewDependencies(db database.Database, conf *config.Config) *Dependencies {
 	userRepo := u.NewUserXormRepository(db)
 	roomRepo := r.NewRoomXormRepository(db)
 	settingRoomRepo := sr.NewSettingRoomXormRepository(db)
 	proposalRepo := p.NewProposalXormRepository(db)
 	voteRepo := v.NewVoteXormRepository(db)
 	optionRepo := o.NewOptionXormRepository(db)
+	delegationRepo := dl.NewDelegationXormRepository(db)
+	lobbyBus := newLobbyBus(db, conf)
 
 	return &Dependencies{
 		UserRepo:        userRepo,
@@ -85,7 +101,41 @@ func NewDependencies(db database.Database) *Dependencies {
 		ProposalRepo:    proposalRepo,
 		VotesRepo:       voteRepo,
 		OptionsRepo:     optionRepo,
+		DelegationRepo:  delegationRepo,
+		LobbyBus:        lobbyBus,
+	}
+}
+
+// tiempos del ping/pong de las salas en vivo, configurables por entorno
+func (s *EchoServer) heartbeat() socketStructs.Heartbeat {
+	heartbeat := socketStructs.DefaultHeartbeat
+	if s.conf.Websocket == nil {
+		return heartbeat
+	}
+
+	heartbeat.PingInterval = time.Duration(s.conf.Websocket.PingInterval) * time.Second
+	heartbeat.PongWait = time.Duration(s.conf.Websocket.PongWait) * time.Second
+	heartbeat.WriteWait = time.Duration(s.conf.Websocket.WriteWait) * time.Second
+
+	//el ping tiene que llegar antes de que venza la espera del pong
+	if heartbeat.PingInterval >= heartbeat.PongWait {
+		heartbeat.PingInterval = heartbeat.PongWait * 9 / 10
 	}
+	return heartbeat
+}
+
+// con varias instancias de la api las salas se replican por postgres. Si no se puede escuchar la api no arranca:
+// con el bus en memoria cada instancia tendria su propia copia de la sala
+func newLobbyBus(db database.Database, conf *config.Config) roomDom.LobbyBus {
+	if conf.LobbyBus != "postgres" {
+		return lobbybus.NewMemoryLobbyBus()
+	}
+
+	bus, err := lobbybus.NewPostgresLobbyBus(db, database.DSN(conf))
+	if err != nil {
+		log.Fatalf("No se pudo iniciar el bus de salas en postgres: %v", err)
+	}
+	return bus
 }
 
 func (s *EchoServer) Start() {
@@ -150,14 +200,16 @@ func (s *EchoServer) Start() {
 	s.app.Use(middleware.Logger())
 
 
-	deps := NewDependencies(s.db)
+	deps := NewDependencies(s.db, s.conf)
 
 	s.InitializeUser(deps.UserRepo, deps.RoomRepo, deps.SettingRoomRepo)
-	s.InitializeRoom(deps.UserRepo, deps.SettingRoomRepo, deps.ProposalRepo, deps.OptionsRepo, deps.VotesRepo)
+	lobbies := s.InitializeRoom(deps.UserRepo, deps.SettingRoomRepo, deps.ProposalRepo, deps.OptionsRepo, deps.VotesRepo, deps.DelegationRepo, deps.LobbyBus)
 	s.InitializeSettingRoom(deps.SettingRoomRepo, deps.RoomRepo)
-	s.InitializeProposal(deps.ProposalRepo, deps.RoomRepo)
+	s.InitializeProposal(deps.ProposalRepo, deps.RoomRepo, deps.SettingRoomRepo)
 	s.InitializeVote()
 	s.InitializeOption()
+	s.InitializeDelegation(deps.DelegationRepo, deps.RoomRepo)
+	s.InitializeScheduler(deps.RoomRepo, lobbies)
 
 	s.app.GET("/v1/health", func(c echo.Context) error {
 		return c.String(200, "OK")
@@ -245,7 +297,9 @@ func (s *EchoServer) InitializeRoom(
 	proposalRepo propDom.ProposalRepository,
 	optionsRepo optDom.OptionRepository,
 	votesRepo voteDom.VoteRepository,
-) {
+	delegationRepo dlDom.DelegationRepository,
+	lobbyBus roomDom.LobbyBus,
+) roomDom.LobbyCloser {
 	roomRepo := r.NewRoomXormRepository(s.db)
 	createRoomUC := roomUsecase.NewCreateUsecase(roomRepo, settingRoomRepo)
 	deleteRoomUC := roomUsecase.NewDeleteUsecase(roomRepo)
@@ -253,13 +307,21 @@ func (s *EchoServer) InitializeRoom(
 	getByIDRoomUC := roomUsecase.NewGetByIDUsecase(roomRepo)
 	getByAdminRoomUC := roomUsecase.NewGetByAdminUsecase(roomRepo)
 	restoreUC := roomUsecase.NewRestoreUsecase(roomRepo)
-	joinUC := roomUsecase.NewJoinRoomUsecase(roomRepo, settingRoomRepo)
+	ManageWsUC := roomWsUsecase.NewManageWsUsecase(roomRepo, userRepo, proposalRepo, optionsRepo, votesRepo, settingRoomRepo, delegationRepo, lobbyBus, s.heartbeat())
+	joinUC := roomUsecase.NewJoinRoomUsecase(roomRepo, settingRoomRepo, ManageWsUC)
 	AddSingleUserUC := roomUsecaseAddUsers.NewAddSingleUserUsecase(roomRepo, userRepo)
 	UpdateRoomUC := roomUsecase.NewUpdateRoomUsecase(roomRepo)
-	ManageWsUC := roomWsUsecase.NewManageWsUsecase(roomRepo, userRepo, proposalRepo, optionsRepo, votesRepo)
 	getSrByRoomIDUC := roomUsecase.NewGetSrByRoomUsecase(roomRepo, settingRoomRepo)
 	HistoryUC := roomUsecase.NewHistoryRoomsUsecase(roomRepo)
 	rmWhitelistUC := roomUsecase.NewWhitelistRmUsecase(roomRepo, userRepo)
+	updateWeightUC := roomUsecase.NewUpdateWeightUsecase(roomRepo)
+	updateRoleUC := roomUsecase.NewUpdateRoleUsecase(roomRepo, userRepo, ManageWsUC)
+	transferUC := roomUsecase.NewTransferOwnershipUsecase(roomRepo, userRepo, ManageWsUC)
+	getRolesUC := roomUsecase.NewGetRolesUsecase(roomRepo)
+	getTimelineUC := roomUsecase.NewGetTimelineUsecase(roomRepo)
+	changeStateUC := roomUsecase.NewChangeStateUsecase(roomRepo)
+	getSessionsUC := roomUsecase.NewGetSessionsUsecase(roomRepo)
+	setOfficialUC := roomUsecase.NewSetOfficialSessionUsecase(roomRepo)
 
 	roomHandler := r.NewRoomEchoHandler(
 		createRoomUC,
@@ -276,9 +338,43 @@ func (s *EchoServer) InitializeRoom(
 		getSrByRoomIDUC,
 		rmWhitelistUC,
 		HistoryUC,
+		updateWeightUC,
+		updateRoleUC,
+		transferUC,
+		getRolesUC,
+		getTimelineUC,
+		changeStateUC,
+		getSessionsUC,
+		setOfficialUC,
 	)
 	r.InitializeRoomEchoRouter(s.app, roomHandler)
 
+	return ManageWsUC
+}
+
+// abre, recuerda y cierra las salas con horario de inicio
+func (s *EchoServer) InitializeScheduler(roomRepo roomDom.RoomRepository, lobbies roomDom.LobbyCloser) {
+	conf := s.conf.Scheduler
+	if conf == nil || !conf.Enabled {
+		return
+	}
+
+	var notifier schedulerDom.Notifier = sc.LogNotifier{}
+	if conf.WebhookURL != "" {
+		notifier = sc.NewWebhookNotifier(conf.WebhookURL)
+	}
+
+	scheduleRepo := sc.NewScheduleXormRepository(s.db)
+	runUC := schedulerUsecase.NewRunUsecase(
+		scheduleRepo,
+		roomRepo,
+		lobbies,
+		notifier,
+		time.Duration(conf.ReminderLead)*time.Second,
+		time.Duration(conf.CloseWindow)*time.Second,
+	)
+
+	sc.NewScheduler(runUC, time.Duration(conf.Interval)*time.Second).Start()
 }
 
 func (s *EchoServer) InitializeSettingRoom(srRepo srDom.SettingRoomRepository, roomRepo roomDom.RoomRepository) {
@@ -300,7 +396,7 @@ func (s *EchoServer) InitializeSettingRoom(srRepo srDom.SettingRoomRepository, r
 	sr.InitializeSettingRoomEchoRouter(s.app, settingRoomHandler)
 }
 
-func (s *EchoServer) InitializeProposal(propRepo propDom.ProposalRepository, roomRepo roomDom.RoomRepository) {
+func (s *EchoServer) InitializeProposal(propRepo propDom.ProposalRepository, roomRepo roomDom.RoomRepository, srRepo srDom.SettingRoomRepository) {
 
 	createProposalUseCase := proposalUsecase.NewCreateUsecase(propRepo, roomRepo)
 	deleteProposalUseCase := proposalUsecase.NewDeleteUseCase(propRepo, roomRepo)
@@ -308,7 +404,7 @@ func (s *EchoServer) InitializeProposal(propRepo propDom.ProposalRepository, roo
 	getProposalByIDUseCase := proposalUsecase.NewGetByIDUseCase(propRepo)
 	updateProposalUseCase := proposalUsecase.NewUpdateProposalUsecase(propRepo, roomRepo)
 	getByRoomUsecase := proposalUsecase.NewGetByRoomUsecase(propRepo)
-	getResultsByRoomUsecase := proposalUsecase.NewGetResultsByRoomUsecase(propRepo)
+	getResultsByRoomUsecase := proposalUsecase.NewGetResultsByRoomUsecase(propRepo, roomRepo, srRepo)
 
 	proposalHandler := p.NewProposalEchoHandler(
 		createProposalUseCase,
@@ -322,3 +418,18 @@ func (s *EchoServer) InitializeProposal(propRepo propDom.ProposalRepository, roo
 
 	p.InitializeProposalEchoRouter(s.app, proposalHandler)
 }
+
+func (s *EchoServer) InitializeDelegation(delegationRepo dlDom.DelegationRepository, roomRepo roomDom.RoomRepository) {
+
+	createDelegationUseCase := delegationUsecase.NewCreateUsecase(delegationRepo, roomRepo)
+	revokeDelegationUseCase := delegationUsecase.NewRevokeUsecase(delegationRepo, roomRepo)
+	getByRoomUseCase := delegationUsecase.NewGetByRoomUsecase(delegationRepo)
+
+	delegationHandler := dl.NewDelegationEchoHandler(
+		createDelegationUseCase,
+		revokeDelegationUseCase,
+		getByRoomUseCase,
+	)
+
+	dl.InitializeDelegationEchoRouter(s.app, delegationHandler)
+}
@@ -0,0 +1,9 @@
+package errors
+
+type alreadyVotedConst string
+
+const ErrAlreadyVoted alreadyVotedConst = "user already voted this proposal."
+
+func (a alreadyVotedConst) Error() string {
+	return string(a)
+}
@@ -4,17 +4,35 @@ import (
 	sv "suffgo/internal/shared/domain/valueObjects"
 )
 
+const (
+	ChoiceOption  = "option"  //voto por una opcion de la propuesta
+	ChoiceAbstain = "abstain" //el votante se abstiene
+	ChoiceBlank   = "blank"   //voto en blanco
+)
+
 type (
 	Vote struct {
-		id       *sv.ID
-		userID   *sv.ID
-		optionID *sv.ID
+		id         *sv.ID
+		userID     *sv.ID
+		optionID   *sv.ID
+		rank       int    //posicion en boletas ranked, 0 en votos simples
+		ballotID   string //solo votos secretos, agrupa las filas de una boleta sin identificar al votante
+		weight     int    //peso del votante al momento de votar
+		proxyID    *sv.ID //delegado que emitio el voto en nombre de userID
+		choice     string
+		proposalID *sv.ID //solo abstenciones y votos en blanco, que no tienen opcion
+		sessionID  *sv.ID //sesion de la sala en la que se emitio
+		round      int    //ronda de la propuesta, mayor a 1 en las re-votaciones
 	}
 
 	VoteDTO struct {
-		ID       uint `json:"id"`
-		UserID   uint `json:"user_id"`
-		OptionID uint `json:"option_id"`
+		ID       uint   `json:"id"`
+		UserID   uint   `json:"user_id"`
+		OptionID uint   `json:"option_id"`
+		Rank     int    `json:"rank,omitempty"`
+		Weight   int    `json:"weight"`
+		ProxyID  uint   `json:"proxy_id,omitempty"`
+		Choice   string `json:"choice"`
 	}
 
 	VoteCreateRequest struct {
@@ -31,17 +49,133 @@ func NewVote(
 		id:       id,
 		userID:   userID,
 		optionID: optionID,
+		weight:   1,
+		choice:   ChoiceOption,
+		round:    1,
 	}
 }
 
+// abstencion o voto en blanco: no tiene opcion y se vincula directamente a la propuesta
+func NewNonOptionVote(
+	id *sv.ID,
+	userID *sv.ID,
+	proposalID *sv.ID,
+	choice string,
+) *Vote {
+	return &Vote{
+		id:         id,
+		userID:     userID,
+		proposalID: proposalID,
+		weight:     1,
+		choice:     choice,
+		round:      1,
+	}
+}
+
+// votos aun no persistidos devuelven el id 0
 func (v *Vote) ID() sv.ID {
+	if v.id == nil {
+		return sv.ID{}
+	}
 	return *v.id
 }
 
+// en votos secretos no hay usuario asociado y devuelve el id 0
 func (v *Vote) UserID() sv.ID {
+	if v.userID == nil {
+		return sv.ID{}
+	}
 	return *v.userID
 }
 
+func (v *Vote) IsSecret() bool {
+	return v.userID == nil
+}
+
+// abstenciones y votos en blanco devuelven el id 0
 func (v *Vote) OptionID() sv.ID {
+	if v.optionID == nil {
+		return sv.ID{}
+	}
 	return *v.optionID
 }
+
+func (v *Vote) Choice() string {
+	return v.choice
+}
+
+func (v *Vote) SetChoice(choice string) {
+	v.choice = choice
+}
+
+// true si el voto no elige ninguna opcion
+func (v *Vote) IsAbstention() bool {
+	return v.choice == ChoiceAbstain || v.choice == ChoiceBlank
+}
+
+func (v *Vote) ProposalID() sv.ID {
+	if v.proposalID == nil {
+		return sv.ID{}
+	}
+	return *v.proposalID
+}
+
+func (v *Vote) SetProposalID(proposalID *sv.ID) {
+	v.proposalID = proposalID
+}
+
+func (v *Vote) Rank() int {
+	return v.rank
+}
+
+func (v *Vote) SetRank(rank int) {
+	v.rank = rank
+}
+
+func (v *Vote) BallotID() string {
+	return v.ballotID
+}
+
+func (v *Vote) SetBallotID(ballotID string) {
+	v.ballotID = ballotID
+}
+
+func (v *Vote) Weight() int {
+	return v.weight
+}
+
+func (v *Vote) SetWeight(weight int) {
+	v.weight = weight
+}
+
+// id del delegado que voto por poder, 0 si voto el propio usuario
+func (v *Vote) ProxyID() sv.ID {
+	if v.proxyID == nil {
+		return sv.ID{}
+	}
+	return *v.proxyID
+}
+
+func (v *Vote) SetProxyID(proxyID *sv.ID) {
+	v.proxyID = proxyID
+}
+
+// 0 en los votos emitidos antes de que existieran las sesiones
+func (v *Vote) SessionID() sv.ID {
+	if v.sessionID == nil {
+		return sv.ID{}
+	}
+	return *v.sessionID
+}
+
+func (v *Vote) SetSessionID(sessionID *sv.ID) {
+	v.sessionID = sessionID
+}
+
+func (v *Vote) Round() int {
+	return v.round
+}
+
+func (v *Vote) SetRound(round int) {
+	v.round = round
+}
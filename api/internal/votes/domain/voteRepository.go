@@ -9,4 +9,9 @@ type VoteRepository interface {
 	GetAll() ([]Vote, error)
 	Delete(id sv.ID) error
 	Save(vote Vote) (*Vote, error)
+	SaveBallot(votes []Vote) ([]Vote, error)
+	SaveSecretBallot(userID sv.ID, proposalID sv.ID, sessionID sv.ID, round int, votes []Vote) ([]Vote, error)
+	ReplaceBallot(previous []Vote, votes []Vote) ([]Vote, error)
+	GetByProposal(proposalID sv.ID, sessionID sv.ID, round int) ([]Vote, error)
+	ParticipantsByProposal(proposalID sv.ID, sessionID sv.ID, round int) ([]sv.ID, error)
 }
@@ -7,11 +7,43 @@ import (
 )
 
 func DomainToModel(vote *domain.Vote) *m.Vote {
-	return &m.Vote{
-		ID:       vote.ID().Id,
-		UserID:   vote.UserID().Id,
-		OptionID: vote.OptionID().Id,
+	voteModel := &m.Vote{
+		ID:     vote.ID().Id,
+		Rank:   vote.Rank(),
+		Weight: vote.Weight(),
+		Choice: vote.Choice(),
+		Round:  vote.Round(),
 	}
+
+	if vote.IsAbstention() {
+		proposalID := vote.ProposalID().Id
+		voteModel.ProposalID = &proposalID
+	} else {
+		optionID := vote.OptionID().Id
+		voteModel.OptionID = &optionID
+	}
+
+	if !vote.IsSecret() {
+		userID := vote.UserID().Id
+		voteModel.UserID = &userID
+	}
+
+	if vote.ProxyID().Id != 0 {
+		proxyID := vote.ProxyID().Id
+		voteModel.ProxyID = &proxyID
+	}
+
+	if vote.BallotID() != "" {
+		ballotID := vote.BallotID()
+		voteModel.BallotID = &ballotID
+	}
+
+	if vote.SessionID().Id != 0 {
+		sessionID := vote.SessionID().Id
+		voteModel.SessionID = &sessionID
+	}
+
+	return voteModel
 }
 
 func ModelToDomain(voteModel *m.Vote) (*domain.Vote, error) {
@@ -19,13 +51,53 @@ func ModelToDomain(voteModel *m.Vote) (*domain.Vote, error) {
 	if err != nil {
 		return nil, err
 	}
-	userID, err := sv.NewID(voteModel.UserID)
-	if err != nil {
-		return nil, err
+
+	//votos secretos no tienen usuario
+	var userID *sv.ID
+	if voteModel.UserID != nil {
+		userID, err = sv.NewID(*voteModel.UserID)
+		if err != nil {
+			return nil, err
+		}
 	}
-	optionID, err := sv.NewID(voteModel.OptionID)
-	if err != nil {
-		return nil, err
+
+	var vote *domain.Vote
+	if voteModel.OptionID == nil {
+		var proposalID *sv.ID
+		if voteModel.ProposalID != nil {
+			proposalID, err = sv.NewID(*voteModel.ProposalID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		vote = domain.NewNonOptionVote(id, userID, proposalID, voteModel.Choice)
+	} else {
+		optionID, err := sv.NewID(*voteModel.OptionID)
+		if err != nil {
+			return nil, err
+		}
+		vote = domain.NewVote(id, userID, optionID)
+	}
+
+	vote.SetRank(voteModel.Rank)
+	vote.SetWeight(voteModel.Weight)
+	vote.SetRound(voteModel.Round)
+	if voteModel.ProxyID != nil {
+		proxyID, err := sv.NewID(*voteModel.ProxyID)
+		if err != nil {
+			return nil, err
+		}
+		vote.SetProxyID(proxyID)
+	}
+	if voteModel.BallotID != nil {
+		vote.SetBallotID(*voteModel.BallotID)
+	}
+	if voteModel.SessionID != nil {
+		sessionID, err := sv.NewID(*voteModel.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		vote.SetSessionID(sessionID)
 	}
-	return domain.NewVote(id, userID, optionID), nil
+	return vote, nil
 }
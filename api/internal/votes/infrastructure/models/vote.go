@@ -1,7 +1,16 @@
 package models
 
 type Vote struct {
-	ID       uint `xorm:"'id' pk autoincr"`
-	UserID   uint `xorm:"'user_id' index not null"`
-	OptionID uint `xorm:"'option_id' index not null"`
+	ID       uint    `xorm:"'id' pk autoincr"`
+	UserID   *uint   `xorm:"'user_id' index null"`   //null en votaciones secretas
+	OptionID *uint   `xorm:"'option_id' index null"` //null en abstenciones y votos en blanco
+	Rank     int     `xorm:"'rank' not null default 0"`
+	BallotID *string `xorm:"'ballot_id' varchar(36) index null"` //agrupa las filas de una misma boleta secreta
+	Weight   int     `xorm:"'weight' not null default 1"`        //peso del votante al momento de votar
+	ProxyID  *uint   `xorm:"'proxy_id' null"`                    //delegado que voto por poder
+
+	Choice     string `xorm:"'choice' varchar(16) not null default 'option'"`
+	ProposalID *uint  `xorm:"'proposal_id' index null"`   //solo abstenciones y votos en blanco
+	SessionID  *uint  `xorm:"'session_id' index null"`    //sesion de la sala en la que se emitio
+	Round      int    `xorm:"'round' not null default 1"` //ronda de la propuesta, mayor a 1 en las re-votaciones
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// copia de una fila de voto antes de ser reemplazada por un cambio de boleta, para auditoria
+type VoteHistory struct {
+	ID         uint      `xorm:"'id' pk autoincr"`
+	VoteID     uint      `xorm:"'vote_id' index not null"`
+	UserID     *uint     `xorm:"'user_id' null"` //null en votaciones secretas
+	OptionID   *uint     `xorm:"'option_id' null"`
+	ProposalID *uint     `xorm:"'proposal_id' null"`
+	Choice     string    `xorm:"'choice' varchar(16) not null"`
+	Rank       int       `xorm:"'rank' not null default 0"`
+	BallotID   *string   `xorm:"'ballot_id' varchar(36) null"`
+	Weight     int       `xorm:"'weight' not null default 1"`
+	ProxyID    *uint     `xorm:"'proxy_id' null"`
+	SessionID  *uint     `xorm:"'session_id' null"`
+	Round      int       `xorm:"'round' not null default 1"`
+	ReplacedAt time.Time `xorm:"'replaced_at' created"`
+}
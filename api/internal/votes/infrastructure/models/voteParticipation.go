@@ -0,0 +1,10 @@
+package models
+
+// en votaciones secretas solo se registra que el usuario voto la propuesta, no que eligio.
+// No tiene id secuencial: el orden de las participaciones no debe poder cruzarse con el de las boletas
+type VoteParticipation struct {
+	UserID     uint `xorm:"'user_id' not null unique(user_proposal_idx)"`
+	ProposalID uint `xorm:"'proposal_id' not null unique(user_proposal_idx)"`
+	SessionID  uint `xorm:"'session_id' not null default 0 unique(user_proposal_idx)"` //se puede volver a votar en otra sesion
+	Round      int  `xorm:"'round' not null default 1 unique(user_proposal_idx)"`      //y en cada ronda de la propuesta
+}
@@ -71,6 +71,10 @@ func (h *VoteEchoHandler) CreateVote(c echo.Context) error {
 		ID:       createVote.ID().Id,
 		UserID:   createVote.UserID().Id,
 		OptionID: createVote.OptionID().Id,
+		Rank:     createVote.Rank(),
+		Weight:   createVote.Weight(),
+		ProxyID:  createVote.ProxyID().Id,
+		Choice:   createVote.Choice(),
 	}
 
 	response := map[string]interface{}{
@@ -115,6 +119,10 @@ func (h *VoteEchoHandler) GetAllVotes(c echo.Context) error {
 			ID:       vote.ID().Id,
 			UserID:   vote.UserID().Id,
 			OptionID: vote.OptionID().Id,
+			Rank:     vote.Rank(),
+			Weight:   vote.Weight(),
+			ProxyID:  vote.ProxyID().Id,
+			Choice:   vote.Choice(),
 		}
 		votesDTO = append(votesDTO, *voteDTO)
 	}
@@ -153,6 +161,10 @@ func (h *VoteEchoHandler) GetVoteByID(c echo.Context) error {
 		ID:       vote.ID().Id,
 		UserID:   vote.UserID().Id,
 		OptionID: vote.OptionID().Id,
+		Rank:     vote.Rank(),
+		Weight:   vote.Weight(),
+		ProxyID:  vote.ProxyID().Id,
+		Choice:   vote.Choice(),
 	}
 
 	msg := fmt.Sprintf("voto con id %d obtenido exitosamente.", id.Id)
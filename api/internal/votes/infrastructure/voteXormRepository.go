@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"math/rand/v2"
+
 	"suffgo/cmd/database"
 	se "suffgo/internal/shared/domain/errors"
 	sv "suffgo/internal/shared/domain/valueObjects"
@@ -8,8 +10,12 @@ import (
 	ve "suffgo/internal/votes/domain/errors"
 	"suffgo/internal/votes/infrastructure/mappers"
 	m "suffgo/internal/votes/infrastructure/models"
+
+	"github.com/google/uuid"
 )
 
+const anonymousIDBase = 1 << 52
+
 type VoteXormRepository struct {
 	db database.Database
 }
@@ -73,10 +79,7 @@ func (s *VoteXormRepository) Delete(id sv.ID) error {
 }
 
 func (s *VoteXormRepository) Save(vote d.Vote) (*d.Vote, error) {
-	voteModel := &m.Vote{
-		UserID:   vote.UserID().Id,
-		OptionID: vote.OptionID().Id,
-	}
+	voteModel := mappers.DomainToModel(&vote)
 
 	_, err := s.db.GetDb().Insert(voteModel)
 	if err != nil {
@@ -90,3 +93,243 @@ func (s *VoteXormRepository) Save(vote d.Vote) (*d.Vote, error) {
 
 	return voteDom, nil
 }
+
+// guarda todas las filas de una boleta (ej: ranked) en una sola transaccion
+func (s *VoteXormRepository) SaveBallot(votes []d.Vote) ([]d.Vote, error) {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+
+	var saved []d.Vote
+	for _, vote := range votes {
+		voteModel := mappers.DomainToModel(&vote)
+
+		if _, err := session.Insert(voteModel); err != nil {
+			session.Rollback()
+			return nil, err
+		}
+
+		voteDom, err := mappers.ModelToDomain(voteModel)
+		if err != nil {
+			session.Rollback()
+			return nil, se.ErrDataMap
+		}
+		saved = append(saved, *voteDom)
+	}
+
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// votacion secreta: registra la participacion del usuario en la sesion y la ronda y guarda las opciones sin usuario,
+// agrupadas por un id de boleta aleatorio. La participacion y la boleta van en transacciones distintas y las filas
+// de la boleta tienen id aleatorio, para que ni la transaccion ni el orden de los ids relacionen al votante con su eleccion
+func (s *VoteXormRepository) SaveSecretBallot(userID sv.ID, proposalID sv.ID, sessionID sv.ID, round int, votes []d.Vote) ([]d.Vote, error) {
+	participation := &m.VoteParticipation{
+		UserID:     userID.Id,
+		ProposalID: proposalID.Id,
+		SessionID:  sessionID.Id,
+		Round:      round,
+	}
+
+	voted, err := s.db.GetDb().Exist(participation)
+	if err != nil {
+		return nil, err
+	}
+	if voted {
+		return nil, ve.ErrAlreadyVoted
+	}
+
+	//el indice unico rechaza un segundo voto que llegue al mismo tiempo
+	if _, err := s.db.GetDb().Insert(participation); err != nil {
+		return nil, err
+	}
+
+	saved, err := s.saveAnonymousBallot(votes)
+	if err != nil {
+		//sin boleta el usuario tiene que poder volver a votar
+		s.db.GetDb().Delete(participation)
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *VoteXormRepository) saveAnonymousBallot(votes []d.Vote) ([]d.Vote, error) {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+
+	ballotID := uuid.New().String()
+
+	var saved []d.Vote
+	for _, vote := range votes {
+		voteModel := mappers.DomainToModel(&vote)
+		voteModel.ID = anonymousVoteID()
+		voteModel.UserID = nil
+		voteModel.ProxyID = nil
+		voteModel.BallotID = &ballotID
+
+		if _, err := session.Insert(voteModel); err != nil {
+			session.Rollback()
+			return nil, err
+		}
+
+		voteDom, err := mappers.ModelToDomain(voteModel)
+		if err != nil {
+			session.Rollback()
+			return nil, se.ErrDataMap
+		}
+		saved = append(saved, *voteDom)
+	}
+
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// id aleatorio para las filas de boletas secretas, por encima de los que genera la secuencia de vote
+// y por debajo de 2^53 para que no pierda precision en el frontend
+func anonymousVoteID() uint {
+	return uint(anonymousIDBase + rand.Uint64N(anonymousIDBase))
+}
+
+// cambio de boleta: guarda las filas anteriores en el historial y actualiza las existentes con la nueva eleccion.
+// Si la boleta nueva tiene mas filas se insertan, si tiene menos se borran las sobrantes
+func (s *VoteXormRepository) ReplaceBallot(previous []d.Vote, votes []d.Vote) ([]d.Vote, error) {
+	session := s.db.GetDb().NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return nil, err
+	}
+
+	for _, vote := range previous {
+		old := mappers.DomainToModel(&vote)
+		history := &m.VoteHistory{
+			VoteID:     old.ID,
+			UserID:     old.UserID,
+			OptionID:   old.OptionID,
+			ProposalID: old.ProposalID,
+			Choice:     old.Choice,
+			Rank:       old.Rank,
+			BallotID:   old.BallotID,
+			Weight:     old.Weight,
+			ProxyID:    old.ProxyID,
+			SessionID:  old.SessionID,
+			Round:      old.Round,
+		}
+		if _, err := session.Insert(history); err != nil {
+			session.Rollback()
+			return nil, err
+		}
+	}
+
+	//las boletas secretas conservan su id de boleta y siguen sin usuario
+	var ballotID *string
+	if len(previous) > 0 && previous[0].BallotID() != "" {
+		id := previous[0].BallotID()
+		ballotID = &id
+	}
+
+	var saved []d.Vote
+	for i, vote := range votes {
+		voteModel := mappers.DomainToModel(&vote)
+		if ballotID != nil {
+			voteModel.UserID = nil
+			voteModel.ProxyID = nil
+			voteModel.BallotID = ballotID
+		}
+
+		if i < len(previous) {
+			voteModel.ID = previous[i].ID().Id
+			_, err := session.ID(voteModel.ID).
+				Cols("user_id", "option_id", "rank", "ballot_id", "weight", "proxy_id", "choice", "proposal_id", "session_id", "round").
+				Update(voteModel)
+			if err != nil {
+				session.Rollback()
+				return nil, err
+			}
+		} else {
+			//las filas nuevas de una boleta secreta tambien llevan id aleatorio
+			if ballotID != nil {
+				voteModel.ID = anonymousVoteID()
+			}
+			if _, err := session.Insert(voteModel); err != nil {
+				session.Rollback()
+				return nil, err
+			}
+		}
+
+		voteDom, err := mappers.ModelToDomain(voteModel)
+		if err != nil {
+			session.Rollback()
+			return nil, se.ErrDataMap
+		}
+		saved = append(saved, *voteDom)
+	}
+
+	for _, vote := range previous[min(len(votes), len(previous)):] {
+		if _, err := session.ID(vote.ID().Id).Delete(&m.Vote{}); err != nil {
+			session.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := session.Commit(); err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+// todas las filas de voto de la ronda de la propuesta en la sesion, incluidas abstenciones y votos en blanco
+func (s *VoteXormRepository) GetByProposal(proposalID sv.ID, sessionID sv.ID, round int) ([]d.Vote, error) {
+	var votes []m.Vote
+	err := s.db.GetDb().
+		Where("(proposal_id = ? OR option_id IN (SELECT id FROM option WHERE proposal_id = ?)) AND session_id = ? AND round = ?", proposalID.Id, proposalID.Id, sessionID.Id, round).
+		OrderBy("id").
+		Find(&votes)
+	if err != nil {
+		return nil, err
+	}
+
+	var votesDomain []d.Vote
+	for _, vote := range votes {
+		voteDomain, err := mappers.ModelToDomain(&vote)
+		if err != nil {
+			return nil, se.ErrDataMap
+		}
+		votesDomain = append(votesDomain, *voteDomain)
+	}
+	return votesDomain, nil
+}
+
+// usuarios que emitieron una boleta secreta en la ronda de la propuesta durante la sesion
+func (s *VoteXormRepository) ParticipantsByProposal(proposalID sv.ID, sessionID sv.ID, round int) ([]sv.ID, error) {
+	var participations []m.VoteParticipation
+	err := s.db.GetDb().Where("proposal_id = ? AND session_id = ? AND round = ?", proposalID.Id, sessionID.Id, round).OrderBy("user_id").Find(&participations)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []sv.ID
+	for _, participation := range participations {
+		userID, err := sv.NewID(participation.UserID)
+		if err != nil {
+			return nil, se.ErrDataMap
+		}
+		users = append(users, *userID)
+	}
+	return users, nil
+}